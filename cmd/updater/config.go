@@ -0,0 +1,144 @@
+package updater
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/icaruk/up-npm/pkg/utils/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	sourceFlag    = "flag"
+	sourceDefault = "default"
+)
+
+// applyConfig finds the project config and uses it as default of every flag not given on the command line.
+// Returns the config and where the value of each flag came from.
+func applyConfig(cmd *cobra.Command) (config.Config, map[string]string, error) {
+
+	sources := map[string]string{}
+
+	file, err := cmd.Flags().GetString(AllowedFlags["file"].Long)
+	if err != nil {
+		return config.Config{}, sources, err
+	}
+
+	projectConfig, err := config.Find(filepath.Dir(file))
+	if err != nil {
+		return config.Config{}, sources, fmt.Errorf("invalid config: %w", err)
+	}
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		sources[flag.Name] = sourceDefault
+		if flag.Changed {
+			sources[flag.Name] = sourceFlag
+		}
+	})
+
+	for key, value := range projectConfig.Flags {
+
+		// Both "no-dev" and "noDev" are fine
+		name := key
+		if allowedFlag, ok := AllowedFlags[key]; ok {
+			name = allowedFlag.Long
+		}
+
+		if cmd.Flags().Lookup(name) == nil {
+			return projectConfig, sources, fmt.Errorf("%s: unknown setting \"%s\"", projectConfig.Path, key)
+		}
+
+		// Command line wins
+		if cmd.Flags().Changed(name) {
+			continue
+		}
+
+		values, err := config.FlagValues(value)
+		if err != nil {
+			return projectConfig, sources, fmt.Errorf("%s: \"%s\": %w", projectConfig.Path, key, err)
+		}
+
+		for _, v := range values {
+			if err := cmd.Flags().Set(name, v); err != nil {
+				return projectConfig, sources, fmt.Errorf("%s: \"%s\": %w", projectConfig.Path, key, err)
+			}
+		}
+
+		sources[name] = projectConfig.Path
+	}
+
+	return projectConfig, sources, nil
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Project configuration (.up-npmrc.json, up-npm.config.json or \"up-npm\" key in package.json)",
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Prints the effective configuration and where each value came from",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		projectConfig, sources, err := applyConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if projectConfig.Path == "" {
+			fmt.Println("No config file found")
+		} else {
+			fmt.Println("Config file:", projectConfig.Path)
+		}
+		fmt.Println()
+
+		var names []string
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Name != "help" {
+				names = append(names, flag.Name)
+			}
+		})
+		sort.Strings(names)
+
+		for _, name := range names {
+			flag := cmd.Flags().Lookup(name)
+			fmt.Printf("%s = %s (%s)\n", name, flag.Value.String(), sources[name])
+		}
+
+		printConfigList := func(title string, lines []string) {
+			if len(lines) == 0 {
+				return
+			}
+
+			fmt.Println()
+			fmt.Printf("%s (%s)\n", title, projectConfig.Path)
+			for _, line := range lines {
+				fmt.Println("  " + line)
+			}
+		}
+
+		printConfigList("ignore", projectConfig.Ignore)
+		printConfigList("ceilings", formatConfigMap(projectConfig.Ceilings))
+		printConfigList("groups", projectConfig.GroupDefinitions())
+		printConfigList("registries", formatConfigMap(projectConfig.Registries))
+
+		var rules []string
+		for _, rule := range projectConfig.Rules {
+			rules = append(rules, rule.String())
+		}
+		printConfigList("rules", rules)
+
+		return nil
+	},
+}
+
+func formatConfigMap(m map[string]string) []string {
+	var lines []string
+	for key, value := range m {
+		lines = append(lines, fmt.Sprintf("%s = %s", key, value))
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -4,20 +4,57 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/icaruk/up-npm/pkg/updater"
+	"github.com/icaruk/up-npm/pkg/utils/filter"
+	grouppkg "github.com/icaruk/up-npm/pkg/utils/group"
+	"github.com/icaruk/up-npm/pkg/utils/httpcache"
+	"github.com/icaruk/up-npm/pkg/utils/license"
 	"github.com/icaruk/up-npm/pkg/utils/npm"
+	packagejson "github.com/icaruk/up-npm/pkg/utils/packagejson"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
 	"github.com/spf13/cobra"
 )
 
 const __VERSION__ string = "4.9.0"
 
 var Cfg = npm.CmdFlags{
-	NoDev:          false,
-	AllowDowngrade: false,
-	Filter:         "",
-	File:           "",
-	UpdatePatches:  false,
+	NoDev:            false,
+	AllowDowngrade:   false,
+	Filter:           "",
+	File:             "",
+	UpdatePatches:    false,
+	Install:          "",
+	Verify:           "",
+	Commit:           "",
+	CommitMessage:    "",
+	Branch:           "",
+	Report:           "",
+	Registry:         npm.DefaultRegistry,
+	NoAudit:          false,
+	SecurityOnly:     false,
+	Advisories:       "",
+	MinReleaseAge:    0,
+	VerifySignatures: false,
+	AllowedLicenses:  nil,
+	NodeVersion:      "",
+	Groups:           nil,
+	AutoGroup:        false,
+	Include:          nil,
+	Exclude:          nil,
+	Only:             nil,
+	Section:          "",
+	Offline:          false,
+	PreferOffline:    false,
+	Concurrency:      npm.DefaultConcurrency,
+	Stream:           false,
+	Check:            false,
+	Json:             "",
+	IncludeLocked:    false,
+	ExcludeLocked:    false,
+	PinStyle:         npm.PinStylePreserve,
 }
 
 type Flag struct {
@@ -42,6 +79,387 @@ var AllowedFlags = map[string]Flag{
 	"updatePatches": {
 		Long: "update-patches",
 	},
+	"install": {
+		Long: "install",
+	},
+	"verify": {
+		Long: "verify",
+	},
+	"commit": {
+		Long: "commit",
+	},
+	"commitMessage": {
+		Long: "commit-message",
+	},
+	"branch": {
+		Long: "branch",
+	},
+	"report": {
+		Long: "report",
+	},
+	"registry": {
+		Long: "registry",
+	},
+	"noAudit": {
+		Long: "no-audit",
+	},
+	"securityOnly": {
+		Long: "security-only",
+	},
+	"advisories": {
+		Long: "advisories",
+	},
+	"minReleaseAge": {
+		Long: "min-release-age",
+	},
+	"verifySignatures": {
+		Long: "verify-signatures",
+	},
+	"allowedLicenses": {
+		Long: "allowed-licenses",
+	},
+	"nodeVersion": {
+		Long: "node-version",
+	},
+	"group": {
+		Long: "group",
+	},
+	"autoGroup": {
+		Long: "auto-group",
+	},
+	"include": {
+		Long: "include",
+	},
+	"exclude": {
+		Long: "exclude",
+	},
+	"only": {
+		Long: "only",
+	},
+	"section": {
+		Long: "section",
+	},
+	"offline": {
+		Long: "offline",
+	},
+	"preferOffline": {
+		Long: "prefer-offline",
+	},
+	"concurrency": {
+		Long: "concurrency",
+	},
+	"stream": {
+		Long: "stream",
+	},
+	"check": {
+		Long: "check",
+	},
+	"json": {
+		Long: "json",
+	},
+	"includeLocked": {
+		Long: "include-locked",
+	},
+	"excludeLocked": {
+		Long: "exclude-locked",
+	},
+	"pinStyle": {
+		Long: "pin-style",
+	},
+}
+
+// getCmdFlags reads the flags shared by the root command and its subcommands
+func getCmdFlags(cmd *cobra.Command) (npm.CmdFlags, error) {
+
+	projectConfig, _, err := applyConfig(cmd)
+	if err != nil {
+		return Cfg, err
+	}
+
+	noDevFlag, err := cmd.Flags().GetBool(AllowedFlags["noDev"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	filterFlag, err := cmd.Flags().GetString(AllowedFlags["filter"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	allowDowngradeFlag, err := cmd.Flags().GetBool(AllowedFlags["allowDowngrade"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	file, err := cmd.Flags().GetString(AllowedFlags["file"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	updatePatches, err := cmd.Flags().GetBool(AllowedFlags["updatePatches"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	install, err := cmd.Flags().GetString(AllowedFlags["install"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	installStrategy, err := packagejson.ParseInstallStrategy(install)
+	if err != nil {
+		return Cfg, err
+	}
+
+	verify, err := cmd.Flags().GetString(AllowedFlags["verify"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	commit, err := cmd.Flags().GetString(AllowedFlags["commit"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if commit != "" && commit != string(updater.CommitPerPackage) && commit != string(updater.CommitPerType) {
+		return Cfg, fmt.Errorf("invalid commit mode \"%s\", allowed values are: package, type", commit)
+	}
+
+	commitMessage, err := cmd.Flags().GetString(AllowedFlags["commitMessage"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	branch, err := cmd.Flags().GetString(AllowedFlags["branch"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if branch != "" && commit == "" {
+		return Cfg, fmt.Errorf("--%s needs --%s", AllowedFlags["branch"].Long, AllowedFlags["commit"].Long)
+	}
+
+	report, err := cmd.Flags().GetString(AllowedFlags["report"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	registry, err := cmd.Flags().GetString(AllowedFlags["registry"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	noAudit, err := cmd.Flags().GetBool(AllowedFlags["noAudit"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	securityOnly, err := cmd.Flags().GetBool(AllowedFlags["securityOnly"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	advisories, err := cmd.Flags().GetString(AllowedFlags["advisories"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	minReleaseAge, err := cmd.Flags().GetDuration(AllowedFlags["minReleaseAge"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if minReleaseAge < 0 {
+		return Cfg, fmt.Errorf("--%s can't be negative", AllowedFlags["minReleaseAge"].Long)
+	}
+
+	verifySignatures, err := cmd.Flags().GetBool(AllowedFlags["verifySignatures"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	allowedLicensesFlag, err := cmd.Flags().GetStringSlice(AllowedFlags["allowedLicenses"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	// "MIT, Apache-2.0" is split on the comma only
+	allowedLicenses := license.ParseAllowedLicenses(strings.Join(allowedLicensesFlag, ","))
+
+	nodeVersion, err := cmd.Flags().GetString(AllowedFlags["nodeVersion"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if nodeVersion != "" {
+		minNodeVersion := versionpkg.MinVersion(nodeVersion)
+		if minNodeVersion == "" {
+			return Cfg, fmt.Errorf("invalid node version \"%s\"", nodeVersion)
+		}
+		nodeVersion = minNodeVersion
+	}
+
+	groups, err := cmd.Flags().GetStringArray(AllowedFlags["group"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	groups = append(groups, projectConfig.GroupDefinitions()...)
+
+	for _, definition := range groups {
+		if _, err := grouppkg.Parse(definition); err != nil {
+			return Cfg, err
+		}
+	}
+
+	autoGroup, err := cmd.Flags().GetBool(AllowedFlags["autoGroup"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	include, err := cmd.Flags().GetStringArray(AllowedFlags["include"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	exclude, err := cmd.Flags().GetStringArray(AllowedFlags["exclude"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if _, err := filter.New(include, exclude); err != nil {
+		return Cfg, err
+	}
+
+	only, err := cmd.Flags().GetStringSlice(AllowedFlags["only"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	for _, updateType := range only {
+		switch versionpkg.UpgradeType(updateType) {
+		case versionpkg.Major, versionpkg.Minor, versionpkg.Patch:
+		default:
+			return Cfg, fmt.Errorf("invalid update type \"%s\", allowed values are: major, minor, patch", updateType)
+		}
+	}
+
+	section, err := cmd.Flags().GetString(AllowedFlags["section"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if section != "" && section != npm.SectionDependencies && section != npm.SectionDevDependencies {
+		return Cfg, fmt.Errorf("invalid section \"%s\", allowed values are: %s, %s", section, npm.SectionDependencies, npm.SectionDevDependencies)
+	}
+
+	offline, err := cmd.Flags().GetBool(AllowedFlags["offline"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	preferOffline, err := cmd.Flags().GetBool(AllowedFlags["preferOffline"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	concurrency, err := cmd.Flags().GetInt(AllowedFlags["concurrency"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if concurrency < 1 {
+		return Cfg, fmt.Errorf("--%s must be at least 1", AllowedFlags["concurrency"].Long)
+	}
+
+	stream, err := cmd.Flags().GetBool(AllowedFlags["stream"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	check, err := cmd.Flags().GetBool(AllowedFlags["check"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	jsonFile, err := cmd.Flags().GetString(AllowedFlags["json"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if jsonFile != "" && !check {
+		return Cfg, fmt.Errorf("--%s needs --%s", AllowedFlags["json"].Long, AllowedFlags["check"].Long)
+	}
+
+	includeLocked, err := cmd.Flags().GetBool(AllowedFlags["includeLocked"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	excludeLocked, err := cmd.Flags().GetBool(AllowedFlags["excludeLocked"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if includeLocked && excludeLocked {
+		return Cfg, fmt.Errorf("--%s and --%s can't be used together", AllowedFlags["includeLocked"].Long, AllowedFlags["excludeLocked"].Long)
+	}
+
+	pinStyle, err := cmd.Flags().GetString(AllowedFlags["pinStyle"].Long)
+	if err != nil {
+		return Cfg, err
+	}
+
+	if !slices.Contains(npm.PinStyles, pinStyle) {
+		return Cfg, fmt.Errorf("invalid pin style \"%s\", allowed values are: %s", pinStyle, strings.Join(npm.PinStyles, ", "))
+	}
+
+	// Nothing is cached without user cache dir
+	cacheDir, _ := httpcache.DefaultDir()
+
+	Cfg = npm.CmdFlags{
+		NoDev:            noDevFlag,
+		Filter:           filterFlag,
+		AllowDowngrade:   allowDowngradeFlag,
+		File:             file,
+		UpdatePatches:    updatePatches,
+		Install:          string(installStrategy),
+		Verify:           verify,
+		Commit:           commit,
+		CommitMessage:    commitMessage,
+		Branch:           branch,
+		Report:           report,
+		Registry:         registry,
+		NoAudit:          noAudit,
+		SecurityOnly:     securityOnly,
+		Advisories:       advisories,
+		MinReleaseAge:    minReleaseAge,
+		VerifySignatures: verifySignatures,
+		AllowedLicenses:  allowedLicenses,
+		NodeVersion:      nodeVersion,
+		Groups:           groups,
+		AutoGroup:        autoGroup,
+		Ignore:           projectConfig.Ignore,
+		Ceilings:         projectConfig.Ceilings,
+		Registries:       projectConfig.Registries,
+		Rules:            projectConfig.Rules,
+		Include:          include,
+		Exclude:          exclude,
+		Only:             only,
+		Section:          section,
+		Offline:          offline,
+		PreferOffline:    preferOffline,
+		Concurrency:      concurrency,
+		Stream:           stream,
+		Check:            check,
+		Json:             jsonFile,
+		IncludeLocked:    includeLocked,
+		ExcludeLocked:    excludeLocked,
+		PinStyle:         pinStyle,
+		CacheDir:         cacheDir,
+	}
+
+	return Cfg, nil
 }
 
 var rootCmd = &cobra.Command{
@@ -50,40 +468,37 @@ var rootCmd = &cobra.Command{
 	Long:  `up-npm is a easy way to keep your npm dependencies up to date.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		noDevFlag, err := cmd.Flags().GetBool(AllowedFlags["noDev"].Long)
+		cfg, err := getCmdFlags(cmd)
 		if err != nil {
 			return err
 		}
 
-		filterFlag, err := cmd.Flags().GetString(AllowedFlags["filter"].Long)
-		if err != nil {
-			return err
+		if cfg.Check {
+			if exitCode := updater.Check(cfg); exitCode != updater.CheckExitUpToDate {
+				os.Exit(exitCode)
+			}
+			return nil
 		}
 
-		allowDowngradeFlag, err := cmd.Flags().GetBool(AllowedFlags["allowDowngrade"].Long)
-		if err != nil {
-			return err
-		}
+		updater.Init(cfg, __VERSION__)
 
-		file, err := cmd.Flags().GetString(AllowedFlags["file"].Long)
-		if err != nil {
-			return err
-		}
+		return nil
+	},
+}
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect",
+	Short: "Finds which dependency updates break the --verify command",
+	Long: `bisect applies the selected updates in halves, re-running install and the --verify command
+until the updates that break it are isolated. package.json is left with the passing updates only.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 
-		updatePatches, err := cmd.Flags().GetBool(AllowedFlags["updatePatches"].Long)
+		cfg, err := getCmdFlags(cmd)
 		if err != nil {
 			return err
 		}
 
-		Cfg = npm.CmdFlags{
-			NoDev:          noDevFlag,
-			Filter:         filterFlag,
-			AllowDowngrade: allowDowngradeFlag,
-			File:           file,
-			UpdatePatches:  updatePatches,
-		}
-
-		updater.Init(Cfg, __VERSION__)
+		updater.Bisect(cfg)
 
 		return nil
 	},
@@ -106,39 +521,233 @@ var whereCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.Flags().BoolVar(
+	rootCmd.PersistentFlags().BoolVar(
 		&Cfg.NoDev,
 		AllowedFlags["noDev"].Long,
 		false,
 		"Exclude dev dependencies",
 	)
-	rootCmd.Flags().StringVarP(
+	rootCmd.PersistentFlags().StringVarP(
 		&Cfg.Filter,
 		AllowedFlags["filter"].Long,
 		AllowedFlags["filter"].Short,
 		"",
 		"Filter dependencies by package name",
 	)
-	rootCmd.Flags().BoolVar(
+	rootCmd.PersistentFlags().BoolVar(
 		&Cfg.AllowDowngrade,
 		AllowedFlags["allowDowngrade"].Long,
 		false,
 		"Allows downgrading a if latest version is older than current",
 	)
-	rootCmd.Flags().StringVar(
+	rootCmd.PersistentFlags().StringVar(
 		&Cfg.File,
 		AllowedFlags["file"].Long,
 		"package.json",
 		"File dependencies by package name",
 	)
-	rootCmd.Flags().BoolVar(
+	rootCmd.PersistentFlags().BoolVar(
 		&Cfg.UpdatePatches,
 		AllowedFlags["updatePatches"].Long,
 		false,
 		"Auto update patch versions without confirmation",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Install,
+		AllowedFlags["install"].Long,
+		"",
+		"Install strategy after updating: targeted, full, lockfile or none (asks if empty)",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Verify,
+		AllowedFlags["verify"].Long,
+		"",
+		"Command to run after installing (e.g. \"npm test\"), package.json and lockfile are restored if it fails",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Commit,
+		AllowedFlags["commit"].Long,
+		"",
+		"Create one git commit per updated package (package) or per update type (type)",
+	)
+	rootCmd.PersistentFlags().Lookup(AllowedFlags["commit"].Long).NoOptDefVal = string(updater.CommitPerPackage)
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.CommitMessage,
+		AllowedFlags["commitMessage"].Long,
+		"",
+		"Commit message template, e.g. \""+updater.DefaultPackageCommitMessage+"\"",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Branch,
+		AllowedFlags["branch"].Long,
+		"",
+		"Create this branch before committing (needs --commit)",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Report,
+		AllowedFlags["report"].Long,
+		"",
+		"Write a markdown summary of the updates to this file (e.g. report.md)",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Registry,
+		AllowedFlags["registry"].Long,
+		npm.DefaultRegistry,
+		"npm registry used to fetch packages and security advisories",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.NoAudit,
+		AllowedFlags["noAudit"].Long,
+		false,
+		"Don't check security advisories of the current versions",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.SecurityOnly,
+		AllowedFlags["securityOnly"].Long,
+		false,
+		"Show only packages whose latest version fixes a security advisory",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Advisories,
+		AllowedFlags["advisories"].Long,
+		"",
+		"Offline OSV advisory database for npm (directory of .json files or .zip) used instead of the registry",
+	)
+
+	rootCmd.PersistentFlags().DurationVar(
+		&Cfg.MinReleaseAge,
+		AllowedFlags["minReleaseAge"].Long,
+		0,
+		"Ignore versions released more recently than this (e.g. 72h), using the newest older version instead",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.VerifySignatures,
+		AllowedFlags["verifySignatures"].Long,
+		false,
+		"Verify the registry signatures of the new versions, flagging the missing or invalid ones",
+	)
+
+	rootCmd.PersistentFlags().StringSliceVar(
+		&Cfg.AllowedLicenses,
+		AllowedFlags["allowedLicenses"].Long,
+		nil,
+		"Block updates changing to a license not in this list (e.g. MIT,Apache-2.0,ISC)",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.NodeVersion,
+		AllowedFlags["nodeVersion"].Long,
+		"",
+		"Node version to check \"engines.node\" against (detected from .nvmrc, .node-version or package.json engines if empty)",
+	)
+
+	rootCmd.PersistentFlags().StringArrayVar(
+		&Cfg.Groups,
+		AllowedFlags["group"].Long,
+		nil,
+		"Update these packages together, e.g. \"nestjs=@nestjs/*\" or \"react=react,react-dom,@types/react\" (repeatable)",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.AutoGroup,
+		AllowedFlags["autoGroup"].Long,
+		false,
+		"Group packages of the same scope updating to the same version",
+	)
+
+	rootCmd.PersistentFlags().StringArrayVar(
+		&Cfg.Include,
+		AllowedFlags["include"].Long,
+		nil,
+		"Only check packages matching a glob (\"@types/*\"), regex (\"/^eslint/\") or scope (\"@babel\") (repeatable)",
+	)
+	rootCmd.PersistentFlags().StringArrayVar(
+		&Cfg.Exclude,
+		AllowedFlags["exclude"].Long,
+		nil,
+		"Don't check packages matching a glob, regex or scope, wins over --include (repeatable)",
+	)
+	rootCmd.PersistentFlags().StringSliceVar(
+		&Cfg.Only,
+		AllowedFlags["only"].Long,
+		nil,
+		"Only show these update types, e.g. minor,patch",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Section,
+		AllowedFlags["section"].Long,
+		"",
+		"Only check this package.json section: dependencies or devDependencies",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.Offline,
+		AllowedFlags["offline"].Long,
+		false,
+		"Use only the cached registry documents, never the network",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.PreferOffline,
+		AllowedFlags["preferOffline"].Long,
+		false,
+		"Use the cached registry documents even if stale, fetching only the missing ones",
+	)
+
+	rootCmd.PersistentFlags().IntVar(
+		&Cfg.Concurrency,
+		AllowedFlags["concurrency"].Long,
+		npm.DefaultConcurrency,
+		"Number of packages fetched from the registry at the same time",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.Stream,
+		AllowedFlags["stream"].Long,
+		false,
+		"Ask for each package as soon as it is fetched, without waiting for the rest",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.Check,
+		AllowedFlags["check"].Long,
+		false,
+		"Only print the outdated packages, exiting with 1 if there are any and 2 if some could not be checked",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.Json,
+		AllowedFlags["json"].Long,
+		"",
+		"Write the outdated packages and the ones that could not be checked as JSON to this file (needs --check)",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.IncludeLocked,
+		AllowedFlags["includeLocked"].Long,
+		false,
+		"Only check the packages locked to an exact version, like \"1.2.3\"",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&Cfg.ExcludeLocked,
+		AllowedFlags["excludeLocked"].Long,
+		false,
+		"Don't check the packages locked to an exact version, they are only listed",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&Cfg.PinStyle,
+		AllowedFlags["pinStyle"].Long,
+		npm.PinStylePreserve,
+		"How the updated versions are written: preserve (keep the current prefix), exact, caret (^) or tilde (~)",
+	)
+
 	rootCmd.AddCommand(whereCmd)
+	rootCmd.AddCommand(bisectCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPrintCmd)
 
 	rootCmd.Version = string(__VERSION__)
 }
@@ -0,0 +1,96 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type packageJsonBackup struct {
+	// original file => backup file
+	files map[string]string
+	// files that did not exist when the backup was created, restoring removes them
+	missingFiles []string
+}
+
+// createPackageJsonBackup copies package.json and its lockfile (if any) next to them as "backup.<date>.<name>"
+func createPackageJsonBackup(file string, lockfile string) (packageJsonBackup, error) {
+	date := time.Now().Format("2006-01-02-15-04-05")
+
+	backup := packageJsonBackup{
+		files: map[string]string{},
+	}
+
+	for _, originalFile := range []string{file, lockfile} {
+		if originalFile == "" {
+			continue
+		}
+
+		fileData, err := os.ReadFile(originalFile)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && originalFile == lockfile {
+				backup.missingFiles = append(backup.missingFiles, originalFile)
+				continue
+			}
+
+			fmt.Println(err)
+			return backup, err
+		}
+
+		backupFileName := filepath.Join(
+			filepath.Dir(originalFile),
+			fmt.Sprintf("backup.%s.%s", date, filepath.Base(originalFile)),
+		)
+
+		err = os.WriteFile(backupFileName, fileData, 0644)
+		if err != nil {
+			fmt.Println(err)
+			return backup, err
+		}
+
+		backup.files[originalFile] = backupFileName
+	}
+
+	return backup, nil
+}
+
+// restore puts back the backed up files and removes the ones that did not exist before
+func (backup packageJsonBackup) restore() error {
+
+	for originalFile, backupFile := range backup.files {
+		fileData, err := os.ReadFile(backupFile)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(originalFile, fileData, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, missingFile := range backup.missingFiles {
+		err := os.Remove(missingFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the backup files
+func (backup packageJsonBackup) remove() error {
+
+	for _, backupFile := range backup.files {
+		err := os.Remove(backupFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/bisect"
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	packagejson "github.com/icaruk/up-npm/pkg/utils/packagejson"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// withSelectedUpdates returns a copy of versionComparison where only the given packages are marked with ShouldUpdate
+func withSelectedUpdates(
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	names []string,
+) map[string]versionpkg.VersionComparisonItem {
+
+	selected := make(map[string]versionpkg.VersionComparisonItem, len(versionComparison))
+
+	for key, value := range versionComparison {
+		value.ShouldUpdate = false
+		selected[key] = value
+	}
+
+	for _, name := range names {
+		if entry, ok := selected[name]; ok {
+			entry.ShouldUpdate = true
+			selected[name] = entry
+		}
+	}
+
+	return selected
+}
+
+// applyUpdates writes package.json with the given updates on top of the original file and installs them
+func applyUpdates(
+	cfg npm.CmdFlags,
+	jsonFile []byte,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	names []string,
+	installStrategy packagejson.InstallStrategy,
+	packageManager string,
+) error {
+
+	appliedComparison := withSelectedUpdates(versionComparison, names)
+
+	err := os.WriteFile(cfg.File, []byte(setPackageJsonVersions(jsonFile, appliedComparison)), 0644)
+	if err != nil {
+		return err
+	}
+
+	installCommands := getInstallCommands(installStrategy, packageManager, appliedComparison)
+
+	// Nothing to install on top, but node_modules still has to match the original package.json
+	if len(installCommands) == 0 {
+		installCommands = []string{packagejson.GetInstallationCommand(packageManager)}
+	}
+
+	return runInstallCommands(installCommands)
+}
+
+// Bisect applies the selected updates in halves, re-running install and the verification command
+// until the updates that break it are isolated. package.json is left with the passing updates only.
+func Bisect(cfg npm.CmdFlags) {
+
+	if cfg.Verify == "" {
+		fmt.Println(aurora.Red("Bisect needs a verification command, use --verify \"npm test\""))
+		return
+	}
+
+	if err := configureNetwork(&cfg); err != nil {
+		fmt.Println(aurora.Red(fmt.Sprintf("Invalid network settings in .npmrc: %s", err)))
+		return
+	}
+
+	versionComparison, sortedPackages, jsonFile, _, ok := loadOutdatedDependencies(cfg)
+	if !ok || len(versionComparison) == 0 {
+		return
+	}
+
+	promptDependencyUpdates(cfg, versionComparison, sortedPackages)
+
+	fmt.Println()
+
+	// Update groups are bisected as a whole
+	candidates := getUpdateUnitNames(versionComparison, sortedPackages)
+
+	if len(candidates) == 0 {
+		fmt.Println(aurora.Yellow("No packages have been selected to update"))
+		return
+	}
+
+	packageManager := packagejson.GetPackageManager()
+	lockfile := packagejson.GetLockfileName(packageManager)
+
+	// Lockfile only or no install at all would verify the old node_modules
+	installStrategy := packagejson.InstallStrategy(cfg.Install)
+	if installStrategy != packagejson.InstallFull {
+		installStrategy = packagejson.InstallTargeted
+	}
+
+	// If the current dependencies already fail every update would look broken
+	if err := runVerifyCommand(cfg.Verify); err != nil {
+		fmt.Println()
+		fmt.Println(err)
+		fmt.Println(aurora.Red("Verification fails without any update, fix it before bisecting"))
+		return
+	}
+
+	fmt.Println()
+
+	backup, err := createPackageJsonBackup(cfg.File, lockfile)
+	if err != nil {
+		fmt.Println(aurora.Red("Could not create a backup, aborting bisect"))
+		return
+	}
+
+	// Each step starts from the backup, it is only kept if it could not be restored
+	keepBackup := false
+	defer func() {
+		if !keepBackup {
+			backup.remove()
+		}
+	}()
+
+	step := 0
+
+	acceptedUnits, culpritUnits := bisect.Bisect(candidates, func(appliedUnits [][]string) bool {
+		step++
+
+		applied := flattenUnits(appliedUnits)
+
+		fmt.Println(
+			aurora.Cyan(fmt.Sprintf("[step %d]", step)),
+			fmt.Sprintf("Trying %d update(s): %s", len(applied), strings.Join(applied, ", ")),
+		)
+
+		if err := backup.restore(); err != nil {
+			keepBackup = true
+			fmt.Println(aurora.Red("Failed to restore backup:"), err)
+			return false
+		}
+
+		err := applyUpdates(cfg, jsonFile, versionComparison, applied, installStrategy, packageManager)
+		if err == nil {
+			err = runVerifyCommand(cfg.Verify)
+		}
+
+		if err != nil {
+			fmt.Println(aurora.Red(fmt.Sprintf("[step %d] failed: %s", step, err)))
+			fmt.Println()
+			return false
+		}
+
+		fmt.Println(aurora.Green(fmt.Sprintf("[step %d] passed", step)))
+		fmt.Println()
+
+		return true
+	})
+
+	accepted := flattenUnits(acceptedUnits)
+	culprits := flattenUnits(culpritUnits)
+
+	// Without culprits the first step already applied everything, otherwise leave package.json
+	// and node_modules with the passing updates only
+	if len(culprits) > 0 {
+		if err := backup.restore(); err != nil {
+			keepBackup = true
+			fmt.Println(aurora.Red("Failed to restore backup:"), err)
+			return
+		}
+
+		err = applyUpdates(cfg, jsonFile, versionComparison, accepted, installStrategy, packageManager)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	var session sessionResult
+	session.setFailed(culprits, "breaks verification")
+
+	writeReport(cfg, versionComparison, session)
+
+	fmt.Println()
+
+	if len(culprits) == 0 {
+		fmt.Println(aurora.Green("All selected updates pass the verification"))
+	} else {
+		fmt.Println(aurora.Red(fmt.Sprintf("%d update(s) break the verification:", len(culprits))))
+		for _, name := range culprits {
+			value := versionComparison[name]
+			fmt.Printf(
+				"  %s %s → %s\n",
+				name,
+				value.Current,
+				versionpkg.ColorizeVersion(value.Latest, value.VersionType),
+			)
+		}
+	}
+
+	fmt.Println()
+
+	fmt.Printf(
+		"✅ %s has been updated with %s\n",
+		cfg.File,
+		aurora.Sprintf(
+			aurora.Green("%d updated packages"),
+			len(accepted),
+		),
+	)
+}
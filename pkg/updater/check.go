@@ -0,0 +1,127 @@
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// Exit codes of --check
+const (
+	CheckExitUpToDate = 0
+	CheckExitOutdated = 1
+	// Some packages could not be checked or package.json could not be read, wins over CheckExitOutdated
+	CheckExitFailed = 2
+)
+
+// jsonOutput is the --json file
+type jsonOutput struct {
+	Outdated []jsonPackage `json:"outdated"`
+	Failures []jsonFailure `json:"failures"`
+}
+
+type jsonPackage struct {
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Type    string `json:"type"`
+	Dev     bool   `json:"dev"`
+	Locked  bool   `json:"locked"`
+}
+
+type jsonFailure struct {
+	Name     string `json:"name"`
+	Dev      bool   `json:"dev"`
+	Category string `json:"category"`
+	Error    string `json:"error"`
+}
+
+// Check prints the outdated packages without asking nor updating anything, returning the exit code
+func Check(cfg npm.CmdFlags) int {
+
+	if err := configureNetwork(&cfg); err != nil {
+		fmt.Println(aurora.Red(fmt.Sprintf("Invalid network settings in .npmrc: %s", err)))
+		return CheckExitFailed
+	}
+
+	versionComparison, _, _, fetchSummary, ok := loadOutdatedDependencies(cfg)
+	if !ok {
+		return CheckExitFailed
+	}
+
+	if !writeJsonOutput(cfg, versionComparison, fetchSummary.Failures) {
+		return CheckExitFailed
+	}
+
+	return getCheckExitCode(versionComparison, fetchSummary.Failures)
+}
+
+func getCheckExitCode(versionComparison map[string]versionpkg.VersionComparisonItem, failures []npm.Failure) int {
+	switch {
+	case len(failures) > 0:
+		return CheckExitFailed
+	case len(versionComparison) > 0:
+		return CheckExitOutdated
+	}
+	return CheckExitUpToDate
+}
+
+// newJsonOutput lists the outdated packages by name, the failures are already sorted
+func newJsonOutput(versionComparison map[string]versionpkg.VersionComparisonItem, failures []npm.Failure) jsonOutput {
+
+	output := jsonOutput{
+		Outdated: []jsonPackage{},
+		Failures: []jsonFailure{},
+	}
+
+	for name, item := range versionComparison {
+		output.Outdated = append(output.Outdated, jsonPackage{
+			Name:    name,
+			Current: item.Current,
+			Latest:  item.Latest,
+			Type:    string(item.VersionType),
+			Dev:     item.IsDev,
+			Locked:  item.IsLocked,
+		})
+	}
+
+	sort.Slice(output.Outdated, func(i, j int) bool {
+		return output.Outdated[i].Name < output.Outdated[j].Name
+	})
+
+	for _, failure := range failures {
+		output.Failures = append(output.Failures, jsonFailure{
+			Name:     failure.Name,
+			Dev:      failure.IsDev,
+			Category: string(failure.Category),
+			Error:    failure.Err.Error(),
+		})
+	}
+
+	return output
+}
+
+// writeJsonOutput writes the --json file, returns false if it could not be written
+func writeJsonOutput(cfg npm.CmdFlags, versionComparison map[string]versionpkg.VersionComparisonItem, failures []npm.Failure) bool {
+
+	if cfg.Json == "" {
+		return true
+	}
+
+	content, err := json.MarshalIndent(newJsonOutput(versionComparison, failures), "", "  ")
+	if err == nil {
+		err = os.WriteFile(cfg.Json, append(content, '\n'), 0644)
+	}
+
+	if err != nil {
+		fmt.Println(aurora.Red(fmt.Sprintf("Could not write %s: %s", cfg.Json, err)))
+		return false
+	}
+
+	return true
+}
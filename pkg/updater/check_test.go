@@ -0,0 +1,60 @@
+package updater
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestGetCheckExitCode(t *testing.T) {
+	outdated := map[string]versionpkg.VersionComparisonItem{"react": {Current: "18.2.0", Latest: "19.0.0"}}
+	failures := []npm.Failure{{Name: "zod", Category: npm.FailureNotFound, Err: errors.New("not found")}}
+
+	testCases := []struct {
+		name              string
+		versionComparison map[string]versionpkg.VersionComparisonItem
+		failures          []npm.Failure
+		expected          int
+	}{
+		{name: "up to date", expected: CheckExitUpToDate},
+		{name: "outdated", versionComparison: outdated, expected: CheckExitOutdated},
+		{name: "failures win", versionComparison: outdated, failures: failures, expected: CheckExitFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exitCode := getCheckExitCode(tc.versionComparison, tc.failures)
+			if exitCode != tc.expected {
+				t.Errorf("expected %v but got %v", tc.expected, exitCode)
+			}
+		})
+	}
+}
+
+func TestNewJsonOutput(t *testing.T) {
+	versionComparison := map[string]versionpkg.VersionComparisonItem{
+		"vitest": {Current: "1.6.0", Latest: "2.0.0", VersionType: versionpkg.Major, IsDev: true},
+		"axios":  {Current: "1.7.2", Latest: "1.7.4", VersionType: versionpkg.Patch, IsLocked: true},
+	}
+	failures := []npm.Failure{
+		{Name: "@corp/ui", Category: npm.FailureUnauthorized, Err: errors.New("unauthorized, check the .npmrc token")},
+	}
+
+	expected := jsonOutput{
+		Outdated: []jsonPackage{
+			{Name: "axios", Current: "1.7.2", Latest: "1.7.4", Type: "patch", Locked: true},
+			{Name: "vitest", Current: "1.6.0", Latest: "2.0.0", Type: "major", Dev: true},
+		},
+		Failures: []jsonFailure{
+			{Name: "@corp/ui", Category: "unauthorized", Error: "unauthorized, check the .npmrc token"},
+		},
+	}
+
+	output := newJsonOutput(versionComparison, failures)
+	if !reflect.DeepEqual(output, expected) {
+		t.Errorf("expected %+v but got %+v", expected, output)
+	}
+}
@@ -0,0 +1,263 @@
+package updater
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/icaruk/up-npm/pkg/utils/git"
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	packagejson "github.com/icaruk/up-npm/pkg/utils/packagejson"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+type CommitMode string
+
+const (
+	CommitPerPackage CommitMode = "package"
+	CommitPerType    CommitMode = "type"
+)
+
+const (
+	DefaultPackageCommitMessage = "chore(deps): bump {{.Name}} from {{.From}} to {{.To}}"
+	DefaultTypeCommitMessage    = "chore(deps): {{.Type}} updates for {{.Packages}}"
+)
+
+// commitMessageData is available on --commit-message templates
+type commitMessageData struct {
+	Name     string // package name, empty when committing per type
+	From     string
+	To       string
+	Type     string // major, minor or patch
+	Count    int
+	Packages string // comma separated package names
+}
+
+type commitGroup struct {
+	names []string
+	data  commitMessageData
+}
+
+// getCommitGroups splits the selected updates into one group per package or one group per update type
+func getCommitGroups(mode CommitMode, versionComparison map[string]versionpkg.VersionComparisonItem) []commitGroup {
+
+	var names []string
+	for key, value := range versionComparison {
+		if value.ShouldUpdate {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	var groups []commitGroup
+
+	if mode == CommitPerType {
+		for _, versionType := range []versionpkg.UpgradeType{versionpkg.Patch, versionpkg.Minor, versionpkg.Major} {
+			var typeNames []string
+			for _, name := range names {
+				if versionComparison[name].VersionType == versionType {
+					typeNames = append(typeNames, name)
+				}
+			}
+
+			if len(typeNames) == 0 {
+				continue
+			}
+
+			groups = append(groups, commitGroup{
+				names: typeNames,
+				data: commitMessageData{
+					Type:     string(versionType),
+					Count:    len(typeNames),
+					Packages: strings.Join(typeNames, ", "),
+				},
+			})
+		}
+
+		return groups
+	}
+
+	// Update groups are committed together
+	groupIndex := map[string]int{}
+
+	for _, name := range names {
+		value := versionComparison[name]
+
+		if value.Group != "" {
+			if i, ok := groupIndex[value.Group]; ok {
+				groups[i].names = append(groups[i].names, name)
+				groups[i].data.Count++
+				groups[i].data.Packages = strings.Join(groups[i].names, ", ")
+				continue
+			}
+
+			groupIndex[value.Group] = len(groups)
+			groups = append(groups, commitGroup{
+				names: []string{name},
+				data: commitMessageData{
+					Name:     value.Group,
+					From:     value.Current,
+					To:       value.Latest,
+					Type:     string(value.VersionType),
+					Count:    1,
+					Packages: name,
+				},
+			})
+			continue
+		}
+
+		groups = append(groups, commitGroup{
+			names: []string{name},
+			data: commitMessageData{
+				Name:     name,
+				From:     value.Current,
+				To:       value.Latest,
+				Type:     string(value.VersionType),
+				Count:    1,
+				Packages: name,
+			},
+		})
+	}
+
+	return groups
+}
+
+// restoreGroupFiles restores the committed files and removes the untracked ones that did not exist before the updates
+func restoreGroupFiles(existedBefore map[string]bool, files ...string) error {
+
+	untracked, err := git.Restore(files...)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range untracked {
+		if existedBefore[file] {
+			fmt.Println(aurora.Yellow(fmt.Sprintf("%s is not committed, it could not be restored", file)))
+			continue
+		}
+
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func renderCommitMessage(messageTemplate string, data commitMessageData) (string, error) {
+	tmpl, err := template.New("commit").Parse(messageTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// commitUpdates applies the selected updates group by group, installing, verifying and committing
+// package.json and the lockfile after each one. Groups failing the verification are rolled back and skipped.
+func commitUpdates(
+	cfg npm.CmdFlags,
+	jsonFile []byte,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	installStrategy packagejson.InstallStrategy,
+	packageManager string,
+	lockfile string,
+) (session sessionResult) {
+
+	mode := CommitMode(cfg.Commit)
+
+	messageTemplate := cfg.CommitMessage
+	if messageTemplate == "" {
+		messageTemplate = DefaultPackageCommitMessage
+		if mode == CommitPerType {
+			messageTemplate = DefaultTypeCommitMessage
+		}
+	}
+
+	var applied []string
+
+	// Only the files created by a rolled back group are removed, the ones that existed before are never deleted
+	existedBefore := map[string]bool{}
+	for _, file := range []string{cfg.File, lockfile} {
+		_, err := os.Stat(file)
+		existedBefore[file] = err == nil
+	}
+
+	for _, group := range getCommitGroups(mode, versionComparison) {
+
+		message, err := renderCommitMessage(messageTemplate, group.data)
+		if err != nil {
+			fmt.Println(aurora.Red("Invalid commit message template:"), err)
+			return session
+		}
+
+		groupComparison := withSelectedUpdates(versionComparison, append(append([]string{}, applied...), group.names...))
+
+		err = os.WriteFile(cfg.File, []byte(setPackageJsonVersions(jsonFile, groupComparison)), 0644)
+		if err != nil {
+			fmt.Println(err)
+			return session
+		}
+
+		// Only the packages of this group, the previous ones are already installed
+		installCommands := getInstallCommands(installStrategy, packageManager, withSelectedUpdates(versionComparison, group.names))
+
+		err = runInstallCommands(installCommands)
+		if err == nil && cfg.Verify != "" {
+			err = runVerifyCommand(cfg.Verify)
+		}
+
+		if err != nil {
+			fmt.Println(err)
+			printBrokenUpdateSet(withSelectedUpdates(versionComparison, group.names))
+
+			session.setFailed(group.names, fmt.Sprintf("failed (%s), not committed", err))
+
+			if err := restoreGroupFiles(existedBefore, cfg.File, lockfile); err != nil {
+				fmt.Println(aurora.Red("Failed to restore files:"), err)
+				return session
+			}
+
+			fmt.Println(aurora.Yellow("Skipped commit:"), message)
+			fmt.Println()
+			continue
+		}
+
+		if err := git.Commit(message, cfg.File, lockfile); err != nil {
+			fmt.Println(aurora.Red("Failed to commit:"), err)
+			return session
+		}
+
+		applied = append(applied, group.names...)
+		session.commits = append(session.commits, message)
+
+		fmt.Println(aurora.Green("Committed:"), message)
+		fmt.Println()
+	}
+
+	fmt.Printf(
+		"✅ %s has been updated with %s in %s\n",
+		cfg.File,
+		aurora.Sprintf(
+			aurora.Green("%d updated packages"),
+			len(applied),
+		),
+		aurora.Sprintf(
+			aurora.Green("%d commits"),
+			len(session.commits),
+		),
+	)
+
+	return session
+}
@@ -0,0 +1,72 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestGetCommitGroups(t *testing.T) {
+	versionComparison := map[string]version.VersionComparisonItem{
+		"axios":   {Current: "1.6.0", Latest: "1.7.2", VersionType: version.Minor, ShouldUpdate: true},
+		"lodash":  {Current: "4.17.20", Latest: "4.17.21", VersionType: version.Patch, ShouldUpdate: true},
+		"react":   {Current: "18.3.1", Latest: "19.0.0", VersionType: version.Major, ShouldUpdate: false},
+		"dayjs":   {Current: "1.11.9", Latest: "1.11.10", VersionType: version.Patch, ShouldUpdate: true},
+		"express": {Current: "4.18.0", Latest: "5.0.0", VersionType: version.Major, ShouldUpdate: true},
+	}
+
+	testCases := []struct {
+		mode             CommitMode
+		messageTemplate  string
+		expectedMessages []string
+	}{
+		{
+			mode:            CommitPerPackage,
+			messageTemplate: DefaultPackageCommitMessage,
+			expectedMessages: []string{
+				"chore(deps): bump axios from 1.6.0 to 1.7.2",
+				"chore(deps): bump dayjs from 1.11.9 to 1.11.10",
+				"chore(deps): bump express from 4.18.0 to 5.0.0",
+				"chore(deps): bump lodash from 4.17.20 to 4.17.21",
+			},
+		},
+		{
+			mode:            CommitPerType,
+			messageTemplate: DefaultTypeCommitMessage,
+			expectedMessages: []string{
+				"chore(deps): patch updates for dayjs, lodash",
+				"chore(deps): minor updates for axios",
+				"chore(deps): major updates for express",
+			},
+		},
+		{
+			mode:            CommitPerType,
+			messageTemplate: "deps: {{.Count}} {{.Type}}",
+			expectedMessages: []string{
+				"deps: 2 patch",
+				"deps: 1 minor",
+				"deps: 1 major",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.messageTemplate, func(t *testing.T) {
+			groups := getCommitGroups(tc.mode, versionComparison)
+
+			if len(groups) != len(tc.expectedMessages) {
+				t.Fatalf("expected %d groups but got %d", len(tc.expectedMessages), len(groups))
+			}
+
+			for i, group := range groups {
+				message, err := renderCommitMessage(tc.messageTemplate, group.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if message != tc.expectedMessages[i] {
+					t.Errorf("expected message %v but got %v", tc.expectedMessages[i], message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/icaruk/up-npm/pkg/utils/cli"
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// getUpdateUnits splits the packages into the units that are updated together: one per group
+// and one per package without group, keeping the order of the first member of each unit
+func getUpdateUnits(packages []versionpkg.PackageVersion) [][]versionpkg.PackageVersion {
+
+	var units [][]versionpkg.PackageVersion
+	groupUnit := map[string]int{}
+
+	for _, pkg := range packages {
+		if pkg.Group == "" {
+			units = append(units, []versionpkg.PackageVersion{pkg})
+			continue
+		}
+
+		if i, ok := groupUnit[pkg.Group]; ok {
+			units[i] = append(units[i], pkg)
+			continue
+		}
+
+		groupUnit[pkg.Group] = len(units)
+		units = append(units, []versionpkg.PackageVersion{pkg})
+	}
+
+	return units
+}
+
+// getUpdateUnitNames is getUpdateUnits for the packages marked with ShouldUpdate, returning only their names
+func getUpdateUnitNames(
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	packages []versionpkg.PackageVersion,
+) [][]string {
+
+	var selected []versionpkg.PackageVersion
+	for _, pkg := range packages {
+		if versionComparison[pkg.Name].ShouldUpdate {
+			selected = append(selected, pkg)
+		}
+	}
+
+	var units [][]string
+	for _, unit := range getUpdateUnits(selected) {
+		var names []string
+		for _, pkg := range unit {
+			names = append(names, pkg.Name)
+		}
+		units = append(units, names)
+	}
+
+	return units
+}
+
+// flattenUnits returns the names of every unit
+func flattenUnits(units [][]string) []string {
+	var names []string
+	for _, unit := range units {
+		names = append(names, unit...)
+	}
+	return names
+}
+
+// promptGroupUpdate asks once for every member of a group, returns true when the user wants to finish
+func promptGroupUpdate(
+	cfg npm.CmdFlags,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	members []versionpkg.PackageVersion,
+	currentCount int,
+	maxCount int,
+) (exit bool) {
+
+	groupName := members[0].Group
+
+	setMembers := func(label string) {
+		for _, member := range members {
+			entry := versionComparison[member.Name]
+			entry.ShouldUpdate = true
+			versionComparison[member.Name] = entry
+
+			fmt.Println(
+				aurora.Sprintf(
+					"%s \"%s\" from %s to %s",
+					aurora.Green(label),
+					member.Name,
+					member.Current,
+					versionpkg.ColorizeVersion(member.Latest, member.VersionType),
+				),
+			)
+		}
+	}
+
+	if cfg.UpdatePatches {
+		allPatches := true
+		for _, member := range members {
+			if member.VersionType != versionpkg.Patch || member.RequiredNode != "" {
+				allPatches = false
+			}
+		}
+
+		if allPatches {
+			setMembers("Auto updated")
+			return false
+		}
+	}
+
+	for {
+		response := cli.PromptUpdateGroup(groupName, members, currentCount, maxCount)
+
+		switch response {
+		case updatePackageOptionLabels.update:
+			setMembers("Updated")
+			return false
+
+		case updatePackageOptionLabels.skip:
+			fmt.Println(aurora.Sprintf(aurora.Faint("Skipped group \"%s\""), groupName))
+			return false
+
+		case updatePackageOptionLabels.show_changes:
+			// Monorepos share the release notes
+			opened := map[string]bool{}
+			for _, member := range members {
+				if opened[member.RepositoryUrl] {
+					continue
+				}
+				opened[member.RepositoryUrl] = true
+
+				openReleaseNotes(cfg, member.VersionComparisonItem)
+			}
+
+		case updatePackageOptionLabels.finish:
+			fmt.Println("Finished update process")
+			return true
+
+		default:
+			return true
+		}
+	}
+}
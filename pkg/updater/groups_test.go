@@ -0,0 +1,32 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestGetUpdateUnitNames(t *testing.T) {
+	versionComparison := map[string]versionpkg.VersionComparisonItem{
+		"react":        {Group: "react", ShouldUpdate: true},
+		"lodash":       {ShouldUpdate: true},
+		"react-dom":    {Group: "react", ShouldUpdate: true},
+		"@types/react": {Group: "react", ShouldUpdate: false},
+		"axios":        {ShouldUpdate: false},
+	}
+
+	sortedPackages := []versionpkg.PackageVersion{
+		{Name: "react", VersionComparisonItem: versionComparison["react"]},
+		{Name: "lodash", VersionComparisonItem: versionComparison["lodash"]},
+		{Name: "axios", VersionComparisonItem: versionComparison["axios"]},
+		{Name: "react-dom", VersionComparisonItem: versionComparison["react-dom"]},
+		{Name: "@types/react", VersionComparisonItem: versionComparison["@types/react"]},
+	}
+
+	expected := [][]string{{"react", "react-dom"}, {"lodash"}}
+
+	if units := getUpdateUnitNames(versionComparison, sortedPackages); !reflect.DeepEqual(units, expected) {
+		t.Errorf("expected %v but got %v", expected, units)
+	}
+}
@@ -0,0 +1,142 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/icaruk/up-npm/pkg/utils/cli"
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	packagejson "github.com/icaruk/up-npm/pkg/utils/packagejson"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+// getInstallCommands returns the commands needed to install the updated packages with the given strategy
+func getInstallCommands(
+	strategy packagejson.InstallStrategy,
+	packageManager string,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+) []string {
+
+	switch strategy {
+	case packagejson.InstallFull:
+		return []string{packagejson.GetInstallationCommand(packageManager)}
+	case packagejson.InstallLockfileOnly:
+		if !packagejson.SupportsLockfileOnly(packageManager) {
+			return nil
+		}
+		return []string{packagejson.GetLockfileOnlyCommand(packageManager)}
+	case packagejson.InstallTargeted:
+		// Exact versions are installed apart so they stay exact on package.json
+		dependencies, devDependencies := getUpdatedPackageSpecs(versionComparison, false)
+		commands := packagejson.GetTargetedInstallationCommands(packageManager, dependencies, devDependencies, false)
+
+		dependencies, devDependencies = getUpdatedPackageSpecs(versionComparison, true)
+		return append(commands, packagejson.GetTargetedInstallationCommands(packageManager, dependencies, devDependencies, true)...)
+	}
+
+	return nil
+}
+
+// getUpdatedPackageSpecs returns "name@version" for every package marked to be updated, split into dependencies and devDependencies.
+// exact selects the packages written without range prefix, the rest otherwise
+func getUpdatedPackageSpecs(
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	exact bool,
+) (dependencies []string, devDependencies []string) {
+
+	for key, value := range versionComparison {
+		if !value.ShouldUpdate || (value.VersionPrefix == "") != exact {
+			continue
+		}
+
+		spec := fmt.Sprintf("%s@%s%s", key, value.VersionPrefix, value.Latest)
+
+		if value.IsDev {
+			devDependencies = append(devDependencies, spec)
+		} else {
+			dependencies = append(dependencies, spec)
+		}
+	}
+
+	sort.Strings(dependencies)
+	sort.Strings(devDependencies)
+
+	return dependencies, devDependencies
+}
+
+// getInstallStrategy returns the install strategy from flags, asking for it when missing
+func getInstallStrategy(
+	cfg npm.CmdFlags,
+	packageManager string,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+) (packagejson.InstallStrategy, error) {
+
+	if cfg.Install != "" {
+		strategy := packagejson.InstallStrategy(cfg.Install)
+
+		if strategy == packagejson.InstallLockfileOnly && !packagejson.SupportsLockfileOnly(packageManager) {
+			return "", fmt.Errorf("%s can't update only the lockfile, use --install targeted or full", packageManager)
+		}
+
+		return strategy, nil
+	}
+
+	return promptInstallStrategy(packageManager, versionComparison)
+}
+
+func promptInstallStrategy(
+	packageManager string,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+) (packagejson.InstallStrategy, error) {
+
+	labels := map[packagejson.InstallStrategy]string{
+		packagejson.InstallTargeted:     "Install only updated packages",
+		packagejson.InstallFull:         "Full install",
+		packagejson.InstallLockfileOnly: "Update lockfile only",
+		packagejson.InstallNone:         "Don't install",
+	}
+
+	var options []string
+	optionStrategies := map[string]packagejson.InstallStrategy{}
+
+	for _, strategy := range packagejson.InstallStrategies {
+		if strategy == packagejson.InstallLockfileOnly && !packagejson.SupportsLockfileOnly(packageManager) {
+			continue
+		}
+
+		option := labels[strategy]
+
+		commands := getInstallCommands(strategy, packageManager, versionComparison)
+		if len(commands) > 0 {
+			option = fmt.Sprintf("%s (%s)", option, strings.Join(commands, " && "))
+		}
+
+		options = append(options, option)
+		optionStrategies[option] = strategy
+	}
+
+	response := ""
+	prompt := &survey.Select{
+		Message: "Install dependencies?",
+		Options: options,
+	}
+	err := survey.AskOne(prompt, &response)
+
+	return optionStrategies[response], err
+}
+
+// runInstallCommands runs each install command in order and stops at the first failure
+func runInstallCommands(commands []string) error {
+
+	for _, command := range commands {
+		fmt.Printf("Running '%s'...\n", command)
+
+		if err := cli.RunCommand(command); err != nil {
+			return fmt.Errorf("'%s' failed: %w", command, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	packagejson "github.com/icaruk/up-npm/pkg/utils/packagejson"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestGetTargetedInstallCommands(t *testing.T) {
+	versionComparison := map[string]version.VersionComparisonItem{
+		"react":  {Latest: "19.0.0", VersionPrefix: "^", ShouldUpdate: true},
+		"lodash": {Latest: "4.17.21", VersionPrefix: "", ShouldUpdate: true},
+		"vitest": {Latest: "2.0.0", VersionPrefix: "", IsDev: true, ShouldUpdate: true},
+		"axios":  {Latest: "1.7.2", VersionPrefix: "~", ShouldUpdate: false},
+	}
+
+	testCases := []struct {
+		packageManager string
+		expected       []string
+	}{
+		{
+			packageManager: "npm",
+			expected: []string{
+				"npm install react@^19.0.0",
+				"npm install --save-exact lodash@4.17.21",
+				"npm install --save-exact -D vitest@2.0.0",
+			},
+		},
+		{
+			packageManager: "pnpm",
+			expected: []string{
+				"pnpm add react@^19.0.0",
+				"pnpm add --save-exact lodash@4.17.21",
+				"pnpm add --save-exact -D vitest@2.0.0",
+			},
+		},
+		{
+			packageManager: "yarn",
+			expected: []string{
+				"yarn add react@^19.0.0",
+				"yarn add --exact lodash@4.17.21",
+				"yarn add --exact -D vitest@2.0.0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.packageManager, func(t *testing.T) {
+			commands := getInstallCommands(packagejson.InstallTargeted, tc.packageManager, versionComparison)
+			if !reflect.DeepEqual(commands, tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, commands)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package updater
+
+import (
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	"github.com/icaruk/up-npm/pkg/utils/npmrc"
+)
+
+// configureNetwork sets the transport with the proxy and TLS settings of .npmrc, used by every request
+// (registry, advisories, GitHub releases)
+func configureNetwork(cfg *npm.CmdFlags) error {
+
+	transport, err := npmrc.GetNetworkConfig(npmrc.GetNpmrcConfig()).NewTransport()
+	if err != nil {
+		return err
+	}
+
+	cfg.Transport = transport
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AlecAivazis/survey/v2"
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// peerConflict is a peer dependency range not satisfied after the selected updates
+type peerConflict struct {
+	Package     string
+	Peer        string
+	PeerRange   string
+	PeerVersion string
+	FixPackage  string // package whose latest version solves the conflict, "" if none
+	FixVersion  string
+}
+
+func (c peerConflict) String() string {
+	return fmt.Sprintf("%s requires %s@%s, but it will be %s", c.Package, c.Peer, c.PeerRange, c.PeerVersion)
+}
+
+// getPeerConflicts checks the peer dependencies of every package against the versions package.json will have after the selected updates
+func getPeerConflicts(
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	currentPackages map[string]npm.CurrentPackage,
+) []peerConflict {
+
+	// Versions and peers after the update
+	versions := map[string]string{}
+	peers := map[string]map[string]string{}
+
+	for name, currentPackage := range currentPackages {
+		versions[name] = currentPackage.Version
+		peers[name] = currentPackage.PeerDependencies
+	}
+	for name, item := range versionComparison {
+		if item.ShouldUpdate {
+			versions[name] = item.Latest
+			peers[name] = item.PeerDependencies
+		}
+	}
+
+	names := make([]string, 0, len(peers))
+	for name := range peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []peerConflict
+
+	for _, name := range names {
+		peerNames := make([]string, 0, len(peers[name]))
+		for peer := range peers[name] {
+			peerNames = append(peerNames, peer)
+		}
+		sort.Strings(peerNames)
+
+		for _, peer := range peerNames {
+			peerRange := peers[name][peer]
+
+			// Peers missing on package.json are not our business
+			peerVersion, ok := versions[peer]
+			if !ok {
+				continue
+			}
+
+			// Conflicts already there before the update are not our business either
+			if !versionComparison[name].ShouldUpdate && !versionComparison[peer].ShouldUpdate {
+				continue
+			}
+
+			satisfies, err := versionpkg.Satisfies(peerVersion, peerRange)
+			if err != nil || satisfies {
+				continue
+			}
+
+			conflict := peerConflict{
+				Package:     name,
+				Peer:        peer,
+				PeerRange:   peerRange,
+				PeerVersion: peerVersion,
+			}
+
+			// Bumping the peer or the package itself may solve it
+			if item, ok := versionComparison[peer]; ok && !item.ShouldUpdate {
+				if satisfies, _ := versionpkg.Satisfies(item.Latest, peerRange); satisfies {
+					conflict.FixPackage, conflict.FixVersion = peer, item.Latest
+				}
+			}
+			if item, ok := versionComparison[name]; ok && !item.ShouldUpdate && conflict.FixPackage == "" {
+				if latestRange, ok := item.PeerDependencies[peer]; ok {
+					if satisfies, _ := versionpkg.Satisfies(peerVersion, latestRange); satisfies {
+						conflict.FixPackage, conflict.FixVersion = name, item.Latest
+					}
+				}
+			}
+
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts
+}
+
+// resolvePeerConflicts prints the peer dependency conflicts of the selected updates, offering to also update the packages that solve them
+func resolvePeerConflicts(
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	currentPackages map[string]npm.CurrentPackage,
+) {
+
+	// Updating a package may bring new conflicts
+	for {
+		conflicts := getPeerConflicts(versionComparison, currentPackages)
+		if len(conflicts) == 0 {
+			return
+		}
+
+		fmt.Println(aurora.Bold(aurora.Yellow("Peer dependency conflicts:")))
+
+		var options []string
+		optionPackages := map[string]string{}
+
+		for _, conflict := range conflicts {
+			fmt.Println("  " + conflict.String())
+
+			if conflict.FixPackage == "" {
+				continue
+			}
+
+			option := fmt.Sprintf("%s to %s", conflict.FixPackage, conflict.FixVersion)
+			if _, ok := optionPackages[option]; !ok {
+				options = append(options, option)
+				optionPackages[option] = conflict.FixPackage
+			}
+		}
+
+		fmt.Println()
+
+		if len(options) == 0 {
+			return
+		}
+
+		var selected []string
+		prompt := &survey.MultiSelect{
+			Message: "Also update these packages to solve the conflicts?",
+			Options: options,
+			Default: options,
+		}
+		if err := survey.AskOne(prompt, &selected); err != nil || len(selected) == 0 {
+			return
+		}
+
+		for _, option := range selected {
+			name := optionPackages[option]
+
+			entry := versionComparison[name]
+			entry.ShouldUpdate = true
+			versionComparison[name] = entry
+
+			fmt.Println(
+				aurora.Sprintf(
+					"%s \"%s\" from %s to %s",
+					aurora.Green("Updated"),
+					name,
+					entry.Current,
+					versionpkg.ColorizeVersion(entry.Latest, entry.VersionType),
+				),
+			)
+		}
+
+		fmt.Println()
+	}
+}
@@ -0,0 +1,86 @@
+package updater
+
+import (
+	"reflect"
+	"testing"
+
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestGetPeerConflicts(t *testing.T) {
+	currentPackages := map[string]npm.CurrentPackage{
+		"react":     {Version: "18.3.1"},
+		"react-dom": {Version: "18.3.1", PeerDependencies: map[string]string{"react": "^18.3.1"}},
+		"ui-kit":    {Version: "2.0.0", PeerDependencies: map[string]string{"react": "^17 || ^18"}},
+		"lodash":    {Version: "4.17.20"},
+	}
+
+	versionComparison := map[string]versionpkg.VersionComparisonItem{
+		"react": {Current: "18.3.1", Latest: "19.0.0", ShouldUpdate: true},
+		"react-dom": {
+			Current:          "18.3.1",
+			Latest:           "19.0.0",
+			PeerDependencies: map[string]string{"react": "^19.0.0"},
+		},
+		"lodash": {Current: "4.17.20", Latest: "4.17.21", ShouldUpdate: true},
+	}
+
+	expected := []peerConflict{
+		{
+			Package:     "react-dom",
+			Peer:        "react",
+			PeerRange:   "^18.3.1",
+			PeerVersion: "19.0.0",
+			FixPackage:  "react-dom",
+			FixVersion:  "19.0.0",
+		},
+		{
+			Package:     "ui-kit",
+			Peer:        "react",
+			PeerRange:   "^17 || ^18",
+			PeerVersion: "19.0.0",
+		},
+	}
+
+	conflicts := getPeerConflicts(versionComparison, currentPackages)
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected %+v but got %+v", expected, conflicts)
+	}
+
+	// Updating react-dom solves its conflict
+	entry := versionComparison["react-dom"]
+	entry.ShouldUpdate = true
+	versionComparison["react-dom"] = entry
+
+	conflicts = getPeerConflicts(versionComparison, currentPackages)
+	if !reflect.DeepEqual(conflicts, expected[1:]) {
+		t.Errorf("expected %+v after the fix but got %+v", expected[1:], conflicts)
+	}
+}
+
+func TestGetPeerConflictsNodeCompatibleVersion(t *testing.T) {
+	currentPackages := map[string]npm.CurrentPackage{
+		"react":        {Version: "18.3.1"},
+		"react-router": {Version: "6.0.0", PeerDependencies: map[string]string{"react": ">=16.8"}},
+	}
+
+	// Latest needs react 19, the node compatible version still works with 18
+	item := versionpkg.VersionComparisonItem{
+		Current:                        "6.0.0",
+		Latest:                         "7.0.0",
+		PeerDependencies:               map[string]string{"react": ">=19"},
+		RequiredNode:                   ">=20",
+		NodeCompatibleVersion:          "6.28.0",
+		NodeCompatiblePeerDependencies: map[string]string{"react": ">=16.8"},
+	}
+
+	item = useNodeCompatibleVersion(item)
+	item.ShouldUpdate = true
+
+	versionComparison := map[string]versionpkg.VersionComparisonItem{"react-router": item}
+
+	if conflicts := getPeerConflicts(versionComparison, currentPackages); len(conflicts) != 0 {
+		t.Errorf("expected no conflicts but got %v", conflicts)
+	}
+}
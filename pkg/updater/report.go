@@ -0,0 +1,115 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	"github.com/icaruk/up-npm/pkg/utils/report"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// sessionResult keeps track of what happened after package.json has been written
+type sessionResult struct {
+	installCommands []string
+	installErr      error
+	verifyCommand   string
+	verifyErr       error
+	rolledBack      bool
+	commits         []string
+	// package name => reason why a selected update has not been applied
+	failed map[string]string
+}
+
+func (session *sessionResult) setFailed(names []string, reason string) {
+	if session.failed == nil {
+		session.failed = map[string]string{}
+	}
+	for _, name := range names {
+		session.failed[name] = reason
+	}
+}
+
+// getReportReleaseNotesUrl links the releases of GitHub repositories, the repository or homepage otherwise.
+// Unlike "Show changes" it makes no GitHub API request, they are rate limited to 60 per hour.
+func getReportReleaseNotesUrl(value versionpkg.VersionComparisonItem) string {
+	switch {
+	case strings.Contains(value.RepositoryUrl, "github.com"):
+		return strings.TrimSuffix(value.RepositoryUrl, "/") + "/releases"
+	case value.RepositoryUrl != "":
+		return value.RepositoryUrl
+	}
+	return value.Homepage
+}
+
+// writeReport writes the --report markdown file summarizing the session
+func writeReport(cfg npm.CmdFlags, versionComparison map[string]versionpkg.VersionComparisonItem, session sessionResult) {
+
+	if cfg.Report == "" {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Generating report...")
+
+	var names []string
+	for key := range versionComparison {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	r := report.Report{
+		File:       cfg.File,
+		RolledBack: session.rolledBack,
+		Commits:    session.commits,
+		Install: report.Step{
+			Command: strings.Join(session.installCommands, " && "),
+			Err:     session.installErr,
+		},
+		Verify: report.Step{
+			Command: session.verifyCommand,
+			Err:     session.verifyErr,
+		},
+	}
+
+	for _, name := range names {
+		value := versionComparison[name]
+
+		pkg := report.Package{
+			Name:              name,
+			From:              value.Current,
+			To:                value.Latest,
+			VersionType:       value.VersionType,
+			IsDev:             value.IsDev,
+			HoursSinceRelease: value.HoursSinceLasRelease,
+		}
+
+		reason, failed := session.failed[name]
+
+		switch {
+		case !value.ShouldUpdate:
+			pkg.Reason = "skipped"
+		case failed:
+			pkg.Reason = reason
+		case session.rolledBack:
+			pkg.Reason = "verification failed, rolled back"
+		default:
+			pkg.ReleaseNotesUrl = getReportReleaseNotesUrl(value)
+			r.Updated = append(r.Updated, pkg)
+			continue
+		}
+
+		r.Skipped = append(r.Skipped, pkg)
+	}
+
+	err := os.WriteFile(cfg.Report, []byte(report.Generate(r)), 0644)
+	if err != nil {
+		fmt.Println(aurora.Red("Failed to write report:"), err)
+		return
+	}
+
+	fmt.Println(aurora.Green(fmt.Sprintf("Report written to %s", cfg.Report)))
+}
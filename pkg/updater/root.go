@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"sort"
 	"strings"
-	"time"
 
+	"github.com/icaruk/up-npm/pkg/utils/advisory"
 	"github.com/icaruk/up-npm/pkg/utils/cli"
+	"github.com/icaruk/up-npm/pkg/utils/config"
+	"github.com/icaruk/up-npm/pkg/utils/git"
+	grouppkg "github.com/icaruk/up-npm/pkg/utils/group"
+	"github.com/icaruk/up-npm/pkg/utils/license"
 	npm "github.com/icaruk/up-npm/pkg/utils/npm"
 	"github.com/icaruk/up-npm/pkg/utils/npmrc"
 	packagejson "github.com/icaruk/up-npm/pkg/utils/packagejson"
@@ -33,9 +37,31 @@ type PackageJSON struct {
 }
 
 func printUpdatablePackagesTable(packages []versionpkg.PackageVersion) {
+
+	// Severity and Notes columns only when some package needs them
+	hasAdvisories := false
+	hasNotes := false
+	for _, pkg := range packages {
+		if pkg.IsVulnerable() {
+			hasAdvisories = true
+		}
+		if getPackageNotes(pkg.VersionComparisonItem) != "" {
+			hasNotes = true
+		}
+	}
+
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Package", "Current", "Latest"})
+
+	header := table.Row{"Package", "Current", "Latest"}
+	if hasAdvisories {
+		header = append(header, "Severity")
+	}
+	if hasNotes {
+		header = append(header, "Notes")
+	}
+	t.AppendHeader(header)
+
 	t.SetColumnConfigs(([]table.ColumnConfig{
 		{
 			Name:  "Package",
@@ -49,17 +75,183 @@ func printUpdatablePackagesTable(packages []versionpkg.PackageVersion) {
 			Name:  "Latest",
 			Align: text.AlignRight,
 		},
+		{
+			Name:  "Severity",
+			Align: text.AlignLeft,
+		},
+		{
+			Name:  "Notes",
+			Align: text.AlignLeft,
+		},
 	}))
 	// Add rows
 	for _, pkg := range packages {
 		latestColorized := versionpkg.ColorizeVersion(pkg.Latest, pkg.VersionType)
-		t.AppendRow(table.Row{pkg.Name, pkg.Current, latestColorized})
+		row := table.Row{pkg.Name, pkg.Current, latestColorized}
+
+		if hasAdvisories {
+			severity := ""
+			if pkg.IsVulnerable() {
+				severity = advisory.ColorizeSeverity(pkg.Severity())
+				if !pkg.LatestFixesAdvisories() {
+					severity += aurora.Faint(" (not fixed)").String()
+				}
+			}
+			row = append(row, severity)
+		}
+
+		if hasNotes {
+			row = append(row, getPackageNotes(pkg.VersionComparisonItem))
+		}
+
+		t.AppendRow(row)
 	}
 
 	t.Render()
 
 }
 
+// getPackageNotes returns the group, held, deprecation, node, signature and release age notes of a package for the table
+func getPackageNotes(item versionpkg.VersionComparisonItem) string {
+
+	var notes []string
+
+	if item.Group != "" {
+		notes = append(notes, aurora.Sprintf(aurora.Cyan("group %s"), item.Group))
+	}
+	if item.HeldReason != "" {
+		notes = append(notes, aurora.Sprintf(aurora.Cyan("held: %s"), item.HeldReason))
+	}
+	if item.CurrentDeprecated != "" {
+		notes = append(notes, aurora.Yellow("current deprecated").String())
+	}
+	if item.LatestDeprecated != "" {
+		note := aurora.Red("latest deprecated").String()
+		if item.SuggestedVersion != "" {
+			note += aurora.Sprintf(aurora.Faint(" (use %s)"), item.SuggestedVersion)
+		}
+		notes = append(notes, note)
+	}
+
+	if item.RequiredNode != "" {
+		notes = append(notes, aurora.Sprintf(aurora.Yellow("requires node %s"), item.RequiredNode))
+	}
+	if item.SignatureError != "" {
+		notes = append(notes, aurora.Red(item.SignatureError).String())
+	}
+	if item.TooRecentVersion != "" {
+		notes = append(notes, aurora.Sprintf(aurora.Faint("%s too recent"), item.TooRecentVersion))
+	}
+
+	return strings.Join(notes, ", ")
+}
+
+// printPackageMessages prints a summary section with one message per package
+func printPackageMessages(title aurora.Value, messages map[string]string) {
+
+	if len(messages) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(messages))
+	for name := range messages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	fmt.Println(title)
+
+	for _, name := range names {
+		fmt.Printf("  %s: %s\n", name, aurora.Faint(messages[name]))
+	}
+
+}
+
+// printFullyDeprecatedPackages prints the packages without any non-deprecated version
+func printFullyDeprecatedPackages(fullyDeprecated map[string]string) {
+	printPackageMessages(aurora.Bold(aurora.Red("Deprecated packages (no version without deprecation):")), fullyDeprecated)
+}
+
+// printLicenseChanges prints the packages whose latest version has a different license
+func printLicenseChanges(packages []versionpkg.PackageVersion) {
+
+	var lines []string
+	for _, pkg := range packages {
+		if !pkg.LicenseChanged() {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"  %s: %s -> %s",
+			pkg.Name,
+			license.Format(pkg.CurrentLicense),
+			aurora.Bold(license.Format(pkg.LatestLicense)),
+		))
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(aurora.Bold(aurora.Yellow("License changes:")))
+	fmt.Println(strings.Join(lines, "\n"))
+
+}
+
+// printBlockedUpdates prints the updates that were excluded because they are not allowed
+func printBlockedUpdates(blocked map[string]string) {
+	printPackageMessages(aurora.Bold(aurora.Red("Blocked updates:")), blocked)
+}
+
+// printHeldPackages prints the packages held back by the rules of the project config
+func printHeldPackages(held map[string]string) {
+	printPackageMessages(aurora.Bold(aurora.Cyan("Held packages:")), held)
+}
+
+// printFetchFailures prints the packages that could not be checked, grouped by the cause of the error
+func printFetchFailures(failures []npm.Failure) {
+
+	if len(failures) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(aurora.Bold(aurora.Red(fmt.Sprintf("Could not check (%d):", len(failures)))))
+
+	for _, failure := range failures {
+		fmt.Printf("  %s: %s %s\n", failure.Name, failure.Category, aurora.Faint(fmt.Sprintf("(%s)", failure.Err)))
+	}
+
+}
+
+// printUnsupportedVersions prints the packages skipped because their version of package.json can't be checked
+func printUnsupportedVersions(unsupported map[string]string) {
+	printPackageMessages(aurora.Bold(aurora.Yellow("Unsupported versions (not checked):")), unsupported)
+}
+
+// printLockedPackages prints the packages locked to an exact version that have an update or were not checked
+func printLockedPackages(locked map[string]string) {
+	printPackageMessages(aurora.Bold(aurora.Blue("Locked packages (exact versions):")), locked)
+}
+
+// printExpiredRules prints the rules of the project config whose date has passed, they are not applied anymore
+func printExpiredRules(rules []config.Rule) {
+
+	if len(rules) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(aurora.Bold(aurora.Yellow("Expired rules (not applied, update or remove them from the config):")))
+
+	for _, rule := range rules {
+		fmt.Printf("  %s\n", aurora.Faint(rule.String()))
+	}
+
+}
+
 func printSummary(totalCount int, majorCount int, minorCount int, patchCount int) {
 
 	baseSt := fmt.Sprintf("Found %d packages to update", totalCount)
@@ -103,10 +295,20 @@ func printSummary(totalCount int, majorCount int, minorCount int, patchCount int
 }
 
 type updatePackageOptions struct {
-	update       string
-	skip         string
-	show_changes string
-	finish       string
+	update                 string
+	update_node_compatible string
+	skip                   string
+	show_changes           string
+	finish                 string
+}
+
+// Must match the options of cli.PromptUpdateDependency
+var updatePackageOptionLabels = updatePackageOptions{
+	update:                 "Update",
+	update_node_compatible: "Update to Node compatible version",
+	skip:                   "Skip",
+	show_changes:           "Show changes",
+	finish:                 "Finish",
 }
 
 type writeJsonOptions struct {
@@ -163,64 +365,14 @@ func initProgressBar(maxBar int) *progressbar.ProgressBar {
 	)
 }
 
-func createPackageJsonBackup(file string) (bool, error) {
-	date := time.Now().Format("2006-01-02-15-04-05")
-
-	backupFileName := fmt.Sprintf("backup.%s.%s", date, file)
-
-	fileData, err := os.ReadFile(file)
-	if err != nil {
-		fmt.Println(err)
-		return false, err
-	}
-
-	err = os.WriteFile(backupFileName, fileData, 0644)
-	if err != nil {
-		fmt.Println(err)
-		return false, err
-	}
-
-	return true, nil
-}
-
-func Init(cfg npm.CmdFlags, binVersion string) {
-
-	var isFilterFilled bool = cfg.Filter != ""
-
-	// Check new version
-	latestRelease, err := repositorypkg.FetchRepositoryLatestRelease("icaruk", "up-npm")
-
-	if err == nil {
-
-		latestReleaseVersion := latestRelease["tag_name"].(string)
-
-		_, upgradeDirection := versionpkg.GetVersionUpdateType(binVersion, latestReleaseVersion)
-
-		if upgradeDirection == versionpkg.UpgradeDirection(UpgradeDirectionUpgrade) {
-
-			fmt.Println()
-
-			fmt.Println(
-				aurora.Sprintf(
-					aurora.BrightGreen("Update: up-npm %s is available!"),
-					aurora.Green(latestReleaseVersion),
-				),
-				aurora.Sprintf(
-					aurora.Faint("(current version is %s)"),
-					aurora.Faint(binVersion),
-				),
-			)
-			// fmt.Println(aurora.Sprintf(aurora.Faint("Current version in %s"), aurora.Faint(binVersion)))
-			fmt.Println(
-				"Click",
-				aurora.Blue("here").Hyperlink("https://github.com/Icaruk/up-npm/releases/latest"),
-				"to check the latest changes.",
-			)
-		}
-
-	}
-
-	fmt.Println()
+// readDependencies reads the dependencies to check from package.json, completing cfg with the node version and .npmrc settings
+func readDependencies(cfg *npm.CmdFlags) (
+	dependencies map[string]string,
+	devDependencies map[string]string,
+	jsonFile []byte,
+	token string,
+	ok bool,
+) {
 
 	// Check .npmrc
 	npmrcFiles, _ := npmrc.GetNpmrcTokens()
@@ -243,25 +395,101 @@ func Init(cfg npm.CmdFlags, binVersion string) {
 	if err != nil {
 		fmt.Println(err)
 		fmt.Println()
-		return
+		return nil, nil, nil, "", false
+	}
+
+	switch cfg.Section {
+	case npm.SectionDependencies:
+		devDependencies = map[string]string{}
+	case npm.SectionDevDependencies:
+		dependencies = map[string]string{}
+	}
+
+	// Node version used to check "engines.node" of the new versions
+	if cfg.NodeVersion == "" {
+		var nodeVersionSource string
+		cfg.NodeVersion, nodeVersionSource = packagejson.GetNodeVersion(cfg.File)
+
+		if cfg.NodeVersion != "" {
+			fmt.Println(aurora.Faint(fmt.Sprintf("Checking compatibility with node %s (%s)", cfg.NodeVersion, nodeVersionSource)))
+			fmt.Println()
+		}
+	}
+	cfg.EngineStrict = npmrc.GetNpmrcConfig()["engine-strict"] == "true"
+
+	if cfg.NoDev {
+		devDependencies = map[string]string{}
 	}
 
-	versionComparison := map[string]versionpkg.VersionComparisonItem{}
+	return dependencies, devDependencies, jsonFile, token, true
+}
+
+/*
+loadOutdatedDependencies fetches the registry for every dependency on package.json and prints the outdated ones.
+
+ok is false if package.json could not be read, versionComparison is empty when everything is up to date.
+*/
+func loadOutdatedDependencies(cfg npm.CmdFlags) (
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	sortedPackages []versionpkg.PackageVersion,
+	jsonFile []byte,
+	fetchSummary npm.FetchSummary,
+	ok bool,
+) {
+
+	var isFilterFilled bool = cfg.HasFilters()
+
+	dependencies, devDependencies, jsonFile, token, ok := readDependencies(&cfg)
+	if !ok {
+		return nil, nil, nil, fetchSummary, false
+	}
+
+	var err error
+
+	versionComparison = map[string]versionpkg.VersionComparisonItem{}
 
 	// Progress bar
 	totalDependencyCount := len(dependencies) + len(devDependencies)
 	bar := initProgressBar(totalDependencyCount)
 
 	// Process dependencies
-	var lockedDependencyCount int
-	var lockedDevDependencyCount int
-
-	lockedDependencyCount = npm.FetchDependencies(dependencies, versionComparison, false, token, bar, cfg)
+	fetchSummary = npm.FetchDependencies(dependencies, versionComparison, false, token, bar, cfg)
 
 	// Process devDependencies
 	if !cfg.NoDev {
-		lockedDevDependencyCount = npm.FetchDependencies(devDependencies, versionComparison, true, token, bar, cfg)
+		fetchSummary.Merge(npm.FetchDependencies(devDependencies, versionComparison, true, token, bar, cfg))
+	}
+
+	// Check security advisories of the current versions
+	if cfg.Advisories != "" {
+		err = npm.LoadAdvisories(versionComparison, cfg)
+		if err != nil {
+			fmt.Println()
+			fmt.Println(aurora.Red(fmt.Sprintf("Could not load advisories from %s: %s", cfg.Advisories, err)))
+		}
+	} else if cfg.Offline && !cfg.NoAudit {
+		fmt.Println()
+		fmt.Println(aurora.Faint("Security advisories are not checked offline, use --advisories with a local database"))
+	} else if !cfg.NoAudit || cfg.SecurityOnly {
+		err = npm.FetchAdvisories(versionComparison, token, cfg)
+		if err != nil {
+			fmt.Println()
+			fmt.Println(aurora.Faint(fmt.Sprintf("Could not check security advisories: %s", err)))
+		}
+	}
+
+	if cfg.SecurityOnly {
+		npm.FilterSecurityUpdates(versionComparison)
+	}
+
+	// Groups are validated when parsing the flags
+	var groups []grouppkg.Group
+	for _, definition := range cfg.Groups {
+		if g, err := grouppkg.Parse(definition); err == nil {
+			groups = append(groups, g)
+		}
 	}
+	grouppkg.Assign(groups, cfg.AutoGroup, versionComparison)
 
 	// Count total dependencies and filtered dependencies
 	filteredDependencyCount := totalDependencyCount
@@ -273,13 +501,20 @@ func Init(cfg npm.CmdFlags, binVersion string) {
 	majorCount, minorCount, patchCount, totalCount := versionpkg.CountVersionTypes(versionComparison)
 
 	// Sort packages by version type
-	sortedPackages := versionpkg.SortPackagesByVersionType(versionComparison)
+	sortedPackages = versionpkg.SortPackagesByVersionType(versionComparison)
 	if filteredDependencyCount == 0 {
 		fmt.Println()
 		fmt.Println()
 		fmt.Println(aurora.Green("No outdated dependencies!"))
+		printFullyDeprecatedPackages(fetchSummary.FullyDeprecated)
+		printBlockedUpdates(fetchSummary.Blocked)
+		printHeldPackages(fetchSummary.Held)
+		printLockedPackages(fetchSummary.Locked)
+		printUnsupportedVersions(fetchSummary.Unsupported)
+		printExpiredRules(npm.GetExpiredRules(cfg.Rules))
+		printFetchFailures(fetchSummary.Failures)
 		fmt.Println()
-		return
+		return versionComparison, nil, jsonFile, fetchSummary, true
 	}
 	// Table
 	fmt.Println("")
@@ -292,131 +527,84 @@ func Init(cfg npm.CmdFlags, binVersion string) {
 		fmt.Println("Filtered", aurora.Blue(filteredDependencyCount), "dependencies from a total of", aurora.Blue(totalDependencyCount))
 	} else {
 		fmt.Println("Total dependencies: ", aurora.Cyan(filteredDependencyCount))
-
-		totalLockedDependencyCount := lockedDependencyCount + lockedDevDependencyCount
-		if totalLockedDependencyCount > 0 {
-			s := fmt.Sprintf("Locked dependencies: %d", totalLockedDependencyCount)
-			fmt.Println(aurora.Faint(s))
-		}
 	}
 
-	printSummary(totalCount, majorCount, minorCount, patchCount)
-
-	fmt.Println()
-
-	// Prompt user to update each dependency
-	updatePackageOptions := updatePackageOptions{
-		update:       "Update",
-		skip:         "Skip",
-		show_changes: "Show changes",
-		finish:       "Finish",
+	if fetchSummary.LockedDependencyCount > 0 {
+		s := fmt.Sprintf("Locked dependencies: %d", fetchSummary.LockedDependencyCount)
+		fmt.Println(aurora.Faint(s))
 	}
 
-	currentUpdateCount := 1
-	maxUpdateCount := len(versionComparison)
+	printSummary(totalCount, majorCount, minorCount, patchCount)
 
-	for _, pkg := range sortedPackages {
+	printFullyDeprecatedPackages(fetchSummary.FullyDeprecated)
+	printLicenseChanges(sortedPackages)
+	printBlockedUpdates(fetchSummary.Blocked)
+	printHeldPackages(fetchSummary.Held)
+	printLockedPackages(fetchSummary.Locked)
+	printUnsupportedVersions(fetchSummary.Unsupported)
+	printExpiredRules(npm.GetExpiredRules(cfg.Rules))
+	printFetchFailures(fetchSummary.Failures)
 
-		key := pkg.Name
-		value := pkg.VersionComparisonItem
+	fmt.Println()
 
-		exit := false
+	return versionComparison, sortedPackages, jsonFile, fetchSummary, true
+}
 
-		for {
+// promptDependencyUpdates asks for each outdated package and marks the accepted ones with ShouldUpdate
+func promptDependencyUpdates(
+	cfg npm.CmdFlags,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	sortedPackages []versionpkg.PackageVersion,
+) {
 
-			if cfg.UpdatePatches {
-				if value.VersionType == versionpkg.Patch {
-					// get a copy of the entry
-					if entry, ok := versionComparison[key]; ok {
-						entry.ShouldUpdate = true      // then modify the copy
-						versionComparison[key] = entry // then reassign map entry
-					}
+	// Packages of the same group are asked together
+	units := getUpdateUnits(sortedPackages)
 
-					colorizedVersion := versionpkg.ColorizeVersion(value.Latest, value.VersionType)
-					fmt.Println(
-						aurora.Sprintf(
-							"%s \"%s\" from %s to %s",
-							aurora.Green("Auto updated"),
-							key,
-							value.Current,
-							colorizedVersion,
-						),
-					)
+	currentUpdateCount := 1
+	maxUpdateCount := len(units)
 
-					break
-				}
-			}
-			response := cli.PromptUpdateDependency(
-				key,
-				value,
-				currentUpdateCount,
-				maxUpdateCount,
-			)
-			if response == updatePackageOptions.skip {
-				// Skipped dependencyName in green color
-				fmt.Println(
-					aurora.Sprintf(
-						aurora.Faint("Skipped \"%s\""),
-						key,
-					),
-				)
+	for _, unit := range units {
 
-				currentUpdateCount++
+		if len(unit) > 1 {
+			exit := promptGroupUpdate(cfg, versionComparison, unit, currentUpdateCount, maxUpdateCount)
+			currentUpdateCount++
 
+			if exit {
 				break
 			}
+			continue
+		}
 
-			if response == updatePackageOptions.show_changes {
-
-				if value.RepositoryUrl == "" {
-					fmt.Println(aurora.Red("Repository URL does not exist"))
-					continue
-				}
-
-				// Get user and repository from repository URL
-				urlMetadata := repositorypkg.GetRepositoryUrlMetadata(value.RepositoryUrl)
-
-				// Fetch repository from github
-				_, err := repositorypkg.FetchRepositoryLatestRelease(urlMetadata.Username, urlMetadata.RepositoryName)
-				missingLatestRelease := err != nil
-
-				var changelogMdUrl string
-
-				if missingLatestRelease {
-					fmt.Println(aurora.Faint("Latest release from github does not exist"))
+		exit := promptPackageUpdate(cfg, versionComparison, unit[0], currentUpdateCount, maxUpdateCount)
+		currentUpdateCount++
 
-					// Fetch CHANGELOG.md
-					response, err := repositorypkg.FetchRepositoryChangelogFile(urlMetadata.Username, urlMetadata.RepositoryName)
+		if exit {
+			break
+		}
 
-					if err == nil {
-						changelogMdUrl = response["html_url"].(string)
-					} else {
-						fmt.Println(aurora.Faint("CHANGELOG.md does not exist"))
-					}
-				}
+	}
 
-				var url string
+}
 
-				if !missingLatestRelease {
-					url = value.RepositoryUrl + "/releases" + "#:~:text=" + value.Current
-				} else if changelogMdUrl != "" {
-					url = changelogMdUrl
-				} else {
-					url = value.Homepage
-				}
+// promptPackageUpdate asks for a single outdated package, marking it with ShouldUpdate if accepted. Returns true on "Finish"
+func promptPackageUpdate(
+	cfg npm.CmdFlags,
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	pkg versionpkg.PackageVersion,
+	currentUpdateCount int,
+	maxUpdateCount int,
+) (exit bool) {
 
-				if url == "" {
-					fmt.Println(aurora.Yellow("No repository or homepage URL found"))
-					continue
-				}
+	updatePackageOptions := updatePackageOptionLabels
 
-				fmt.Println("Opening...")
-				fmt.Println()
-				cli.Openbrowser(url)
+	key := pkg.Name
+	value := pkg.VersionComparisonItem
 
-			}
+	for {
 
-			if response == updatePackageOptions.update {
+		if cfg.UpdatePatches {
+			// Patches requiring a newer node are asked
+			if value.VersionType == versionpkg.Patch && value.RequiredNode == "" {
 				// get a copy of the entry
 				if entry, ok := versionComparison[key]; ok {
 					entry.ShouldUpdate = true      // then modify the copy
@@ -424,46 +612,248 @@ func Init(cfg npm.CmdFlags, binVersion string) {
 				}
 
 				colorizedVersion := versionpkg.ColorizeVersion(value.Latest, value.VersionType)
-
 				fmt.Println(
 					aurora.Sprintf(
 						"%s \"%s\" from %s to %s",
-						aurora.Green("Updated"),
+						aurora.Green("Auto updated"),
 						key,
 						value.Current,
 						colorizedVersion,
 					),
 				)
 
-				currentUpdateCount++
-
 				break
 			}
+		}
+		response := cli.PromptUpdateDependency(
+			key,
+			value,
+			currentUpdateCount,
+			maxUpdateCount,
+		)
+		if response == updatePackageOptions.skip {
+			// Skipped dependencyName in green color
+			fmt.Println(
+				aurora.Sprintf(
+					aurora.Faint("Skipped \"%s\""),
+					key,
+				),
+			)
 
-			if response == updatePackageOptions.finish {
-				fmt.Println("Finished update process")
-				exit = true
-				break
+			break
+		}
+
+		if response == updatePackageOptions.show_changes {
+			openReleaseNotes(cfg, value)
+		}
+
+		if response == updatePackageOptions.update_node_compatible {
+			value = useNodeCompatibleVersion(value)
+			versionComparison[key] = value
+
+			response = updatePackageOptions.update
+		}
+
+		if response == updatePackageOptions.update {
+			// get a copy of the entry
+			if entry, ok := versionComparison[key]; ok {
+				entry.ShouldUpdate = true      // then modify the copy
+				versionComparison[key] = entry // then reassign map entry
 			}
+
+			colorizedVersion := versionpkg.ColorizeVersion(value.Latest, value.VersionType)
+
+			fmt.Println(
+				aurora.Sprintf(
+					"%s \"%s\" from %s to %s",
+					aurora.Green("Updated"),
+					key,
+					value.Current,
+					colorizedVersion,
+				),
+			)
+
+			break
 		}
 
-		if exit {
+		if response == updatePackageOptions.finish {
+			fmt.Println("Finished update process")
+			exit = true
 			break
 		}
+	}
+
+	return exit
+}
+
+// useNodeCompatibleVersion updates to the newest version supporting our node instead of latest
+func useNodeCompatibleVersion(value versionpkg.VersionComparisonItem) versionpkg.VersionComparisonItem {
+	value.Latest = value.NodeCompatibleVersion
+	value.PeerDependencies = value.NodeCompatiblePeerDependencies
+	value.VersionType, _ = versionpkg.GetVersionUpdateType(value.Current, value.Latest)
+	value.RequiredNode = ""
+	value.NodeCompatibleVersion = ""
+	value.NodeCompatiblePeerDependencies = nil
+	return value
+}
+
+// openReleaseNotes opens the release notes of a package on the browser
+func openReleaseNotes(cfg npm.CmdFlags, value versionpkg.VersionComparisonItem) {
+
+	if value.RepositoryUrl == "" {
+		fmt.Println(aurora.Red("Repository URL does not exist"))
+		return
+	}
+
+	url, source := repositorypkg.GetReleaseNotesUrl(cfg.HttpClient(), value.RepositoryUrl, value.Current, value.Homepage)
+
+	if source != repositorypkg.ReleaseNotesGithubReleases {
+		fmt.Println(aurora.Faint("Latest release from github does not exist"))
+	}
+	if source == repositorypkg.ReleaseNotesHomepage || source == repositorypkg.ReleaseNotesNone {
+		fmt.Println(aurora.Faint("CHANGELOG.md does not exist"))
+	}
 
+	if url == "" {
+		fmt.Println(aurora.Yellow("No repository or homepage URL found"))
+		return
 	}
 
+	fmt.Println("Opening...")
 	fmt.Println()
+	cli.Openbrowser(url)
 
-	// ------------------------------------------
+}
 
-	// Check how many updates are on versionComparison with value.shouldUpdate = true
+// countSelectedUpdates returns how many packages are marked with ShouldUpdate
+func countSelectedUpdates(versionComparison map[string]versionpkg.VersionComparisonItem) int {
 	var shouldUpdateCount int
 	for _, value := range versionComparison {
 		if value.ShouldUpdate {
 			shouldUpdateCount++
 		}
 	}
+	return shouldUpdateCount
+}
+
+// setPackageJsonVersions returns package.json content with the latest version of every package marked with ShouldUpdate
+func setPackageJsonVersions(jsonFile []byte, versionComparison map[string]versionpkg.VersionComparisonItem) string {
+
+	// Stringify package.json
+	jsonFileStr := string(jsonFile)
+
+	// Write dependencies to package.json
+	for key, value := range versionComparison {
+		if value.ShouldUpdate {
+
+			dependenciesKeyName := "dependencies"
+			if value.IsDev {
+				dependenciesKeyName = "devDependencies"
+			}
+
+			// If key includes a dor `.` replace with `\.`
+			if strings.Contains(key, ".") {
+				key = strings.ReplaceAll(key, ".", `\.`)
+			}
+
+			dotPath := fmt.Sprintf("%s.%s", dependenciesKeyName, key)
+			latestVersion := fmt.Sprintf("%s%s", value.VersionPrefix, value.Latest)
+
+			jsonFileStr, _ = sjson.Set(jsonFileStr, dotPath, latestVersion)
+		}
+	}
+
+	return jsonFileStr
+}
+
+func Init(cfg npm.CmdFlags, binVersion string) {
+
+	if err := configureNetwork(&cfg); err != nil {
+		fmt.Println(aurora.Red(fmt.Sprintf("Invalid network settings in .npmrc: %s", err)))
+		return
+	}
+
+	// Check new version
+	latestRelease, err := repositorypkg.FetchRepositoryLatestRelease(cfg.HttpClient(), "icaruk", "up-npm")
+
+	if err == nil {
+
+		latestReleaseVersion := latestRelease["tag_name"].(string)
+
+		_, upgradeDirection := versionpkg.GetVersionUpdateType(binVersion, latestReleaseVersion)
+
+		if upgradeDirection == versionpkg.UpgradeDirection(UpgradeDirectionUpgrade) {
+
+			fmt.Println()
+
+			fmt.Println(
+				aurora.Sprintf(
+					aurora.BrightGreen("Update: up-npm %s is available!"),
+					aurora.Green(latestReleaseVersion),
+				),
+				aurora.Sprintf(
+					aurora.Faint("(current version is %s)"),
+					aurora.Faint(binVersion),
+				),
+			)
+			// fmt.Println(aurora.Sprintf(aurora.Faint("Current version in %s"), aurora.Faint(binVersion)))
+			fmt.Println(
+				"Click",
+				aurora.Blue("here").Hyperlink("https://github.com/Icaruk/up-npm/releases/latest"),
+				"to check the latest changes.",
+			)
+		}
+
+	}
+
+	fmt.Println()
+
+	// Commits must only contain our changes
+	if cfg.Commit != "" {
+		if !git.IsRepository() {
+			fmt.Println(aurora.Red("--commit needs a git repository"))
+			return
+		}
+
+		lockfile := packagejson.GetLockfileName(packagejson.GetPackageManager())
+		if hasChanges, err := git.HasChanges(cfg.File, lockfile); err != nil || hasChanges {
+			fmt.Println(aurora.Red(fmt.Sprintf("--commit needs %s and %s without uncommitted changes", cfg.File, lockfile)))
+			return
+		}
+	}
+
+	var versionComparison map[string]versionpkg.VersionComparisonItem
+	var jsonFile []byte
+	var fetchSummary npm.FetchSummary
+	var ok bool
+
+	if cfg.Stream && canStream(cfg) {
+		versionComparison, jsonFile, fetchSummary, ok = streamDependencyUpdates(cfg)
+		if !ok || len(versionComparison) == 0 {
+			return
+		}
+	} else {
+		if cfg.Stream {
+			fmt.Println(aurora.Faint("--stream is ignored with update groups, every package is needed before asking"))
+		}
+
+		var sortedPackages []versionpkg.PackageVersion
+		versionComparison, sortedPackages, jsonFile, fetchSummary, ok = loadOutdatedDependencies(cfg)
+		if !ok || len(versionComparison) == 0 {
+			return
+		}
+
+		promptDependencyUpdates(cfg, versionComparison, sortedPackages)
+	}
+
+	fmt.Println()
+
+	resolvePeerConflicts(versionComparison, fetchSummary.Current)
+
+	// ------------------------------------------
+
+	// Check how many updates are on versionComparison with value.shouldUpdate = true
+	shouldUpdateCount := countSelectedUpdates(versionComparison)
 
 	if shouldUpdateCount == 0 {
 		fmt.Println(aurora.Yellow("No packages have been selected to update"))
@@ -497,34 +887,59 @@ func Init(cfg npm.CmdFlags, binVersion string) {
 		return
 	}
 
-	if response == writeJsonOptions.yes_backup {
-		createPackageJsonBackup(cfg.File)
-	}
+	packageManager := packagejson.GetPackageManager()
+	lockfile := packagejson.GetLockfileName(packageManager)
 
-	// Stringify package.json
-	jsonFileStr := string(jsonFile)
+	// Verification needs a backup to roll back to
+	var backup packageJsonBackup
 
-	// Write dependencies to package.json
-	for key, value := range versionComparison {
-		if value.ShouldUpdate {
+	if response == writeJsonOptions.yes_backup || cfg.Verify != "" {
+		backup, err = createPackageJsonBackup(cfg.File, lockfile)
 
-			dependenciesKeyName := "dependencies"
-			if value.IsDev {
-				dependenciesKeyName = "devDependencies"
-			}
+		if err != nil && cfg.Verify != "" {
+			fmt.Println(aurora.Red("Could not create a backup, aborting update process"))
+			return
+		}
+	}
 
-			// If key includes a dor `.` replace with `\.`
-			if strings.Contains(key, ".") {
-				key = strings.ReplaceAll(key, ".", `\.`)
+	// The backup of --verify is only kept if it could not be restored
+	keepBackup := response == writeJsonOptions.yes_backup
+	defer func() {
+		if !keepBackup {
+			backup.remove()
+		}
+	}()
+
+	var session sessionResult
+	defer func() {
+		writeReport(cfg, versionComparison, session)
+	}()
+
+	if cfg.Commit != "" {
+		installStrategy, err := getInstallStrategy(cfg, packageManager, versionComparison)
+		if err != nil {
+			if err == terminal.InterruptErr {
+				fmt.Println("")
+			} else {
+				fmt.Println(aurora.Red(err.Error()))
 			}
+			return
+		}
 
-			dotPath := fmt.Sprintf("%s.%s", dependenciesKeyName, key)
-			latestVersion := fmt.Sprintf("%s%s", value.VersionPrefix, value.Latest)
-
-			jsonFileStr, _ = sjson.Set(jsonFileStr, dotPath, latestVersion)
+		if cfg.Branch != "" {
+			if err := git.CreateBranch(cfg.Branch); err != nil {
+				fmt.Println(aurora.Red("Failed to create branch:"), err)
+				return
+			}
 		}
+
+		fmt.Println()
+		session = commitUpdates(cfg, jsonFile, versionComparison, installStrategy, packageManager, lockfile)
+		return
 	}
 
+	jsonFileStr := setPackageJsonVersions(jsonFile, versionComparison)
+
 	// Write to file
 	err = os.WriteFile(cfg.File, []byte(jsonFileStr), 0644)
 	if err != nil {
@@ -544,37 +959,62 @@ func Init(cfg npm.CmdFlags, binVersion string) {
 
 	fmt.Println()
 
-	packageManager := packagejson.GetPackageManager()
-	installationCommand := packagejson.GetInstallationCommand(packageManager)
-
-	installPromptMessage := fmt.Sprintf("Run '%s' to install dependencies?", installationCommand)
-	response, err = cli.PromptYesNo(installPromptMessage)
-
+	installStrategy, err := getInstallStrategy(cfg, packageManager, versionComparison)
 	if err != nil {
 		if err == terminal.InterruptErr {
 			fmt.Println("")
+		} else {
+			fmt.Println(aurora.Red(err.Error()))
 		}
+		return
 	}
 
-	if response == cli.YesNoPromptOptions.Yes {
-		fmt.Printf("Running '%s'...", installationCommand)
+	installCommands := getInstallCommands(installStrategy, packageManager, versionComparison)
+
+	err = runInstallCommands(installCommands)
+	if err != nil {
+		fmt.Println(err)
+	}
 
-		// Split command and args
-		commandAndArgs := strings.Split(installationCommand, " ")
-		command := commandAndArgs[0]
-		args := commandAndArgs[1:]
+	session.installCommands = installCommands
+	session.installErr = err
 
-		// Execute command
-		cmd := exec.Command(command, args...)
+	if cfg.Verify == "" {
+		return
+	}
 
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	// Skip verification when the install itself failed
+	if err == nil {
+		fmt.Println()
+		err = runVerifyCommand(cfg.Verify)
 
-		if err := cmd.Run(); err != nil {
-			fmt.Println(err)
-		}
+		session.verifyCommand = cfg.Verify
+		session.verifyErr = err
+	}
 
+	fmt.Println()
+
+	if err == nil {
+		fmt.Println(aurora.Green("✅ Verification passed"))
+		return
+	}
+
+	fmt.Println(err)
+	printBrokenUpdateSet(versionComparison)
+
+	if err := backup.restore(); err != nil {
+		keepBackup = true
+		fmt.Println(aurora.Red("Failed to restore backup:"), err)
 		return
 	}
 
+	session.rolledBack = true
+
+	fmt.Println()
+	fmt.Println(aurora.Yellow(fmt.Sprintf("%s and lockfile have been restored from backup", cfg.File)))
+
+	if len(installCommands) > 0 {
+		fmt.Println(aurora.Faint(fmt.Sprintf("Run '%s' to restore node_modules", packagejson.GetInstallationCommand(packageManager))))
+	}
+
 }
@@ -0,0 +1,119 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/icaruk/up-npm/pkg/utils/advisory"
+	npm "github.com/icaruk/up-npm/pkg/utils/npm"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// canStream checks if the updates can be asked while loading, update groups need every package first
+func canStream(cfg npm.CmdFlags) bool {
+	return len(cfg.Groups) == 0 && !cfg.AutoGroup
+}
+
+/*
+streamDependencyUpdates asks for each outdated package as soon as it is fetched, while the rest are still loading.
+
+There is no table before the prompts, the summary sections are printed once every package has been checked.
+ok is false if package.json could not be read, versionComparison is empty when everything is up to date.
+*/
+func streamDependencyUpdates(cfg npm.CmdFlags) (
+	versionComparison map[string]versionpkg.VersionComparisonItem,
+	jsonFile []byte,
+	summary npm.FetchSummary,
+	ok bool,
+) {
+
+	dependencies, devDependencies, jsonFile, token, ok := readDependencies(&cfg)
+	if !ok {
+		return nil, nil, summary, false
+	}
+
+	// The offline database is loaded once for every package
+	var osvAdvisories map[string][]advisory.Advisory
+	if cfg.Advisories != "" {
+		var err error
+		osvAdvisories, err = advisory.LoadOSV(cfg.Advisories)
+		if err != nil {
+			fmt.Println(aurora.Red(fmt.Sprintf("Could not load advisories from %s: %s", cfg.Advisories, err)))
+		}
+	} else if cfg.Offline && !cfg.NoAudit {
+		fmt.Println(aurora.Faint("Security advisories are not checked offline, use --advisories with a local database"))
+	}
+
+	fmt.Println(aurora.Faint(fmt.Sprintf("Checking %d dependencies...", len(dependencies)+len(devDependencies))))
+
+	versionComparison = map[string]versionpkg.VersionComparisonItem{}
+	summary = npm.NewFetchSummary()
+
+	currentUpdateCount := 1
+	exit := false
+	advisoriesFailed := false
+
+	for result := range npm.StreamDependencies(dependencies, devDependencies, token, cfg) {
+
+		summary.Add(result)
+
+		if result.Item == nil {
+			continue
+		}
+
+		item := map[string]versionpkg.VersionComparisonItem{result.Name: *result.Item}
+
+		switch {
+		case cfg.Advisories != "":
+			npm.SetAdvisories(item, osvAdvisories)
+		case cfg.Offline:
+		case !cfg.NoAudit || cfg.SecurityOnly:
+			if err := npm.FetchAdvisories(item, token, cfg); err != nil && !advisoriesFailed {
+				advisoriesFailed = true
+				fmt.Println(aurora.Faint(fmt.Sprintf("Could not check security advisories: %s", err)))
+			}
+		}
+
+		if cfg.SecurityOnly {
+			npm.FilterSecurityUpdates(item)
+		}
+
+		value, isOutdated := item[result.Name]
+		if !isOutdated {
+			continue
+		}
+
+		versionComparison[result.Name] = value
+
+		// Keep loading the rest for the summary
+		if exit {
+			continue
+		}
+
+		pkg := versionpkg.PackageVersion{Name: result.Name, VersionComparisonItem: value}
+		exit = promptPackageUpdate(cfg, versionComparison, pkg, currentUpdateCount, 0)
+		currentUpdateCount++
+	}
+
+	if len(versionComparison) == 0 {
+		fmt.Println()
+		fmt.Println(aurora.Green("No outdated dependencies!"))
+	}
+
+	if summary.LockedDependencyCount > 0 {
+		fmt.Println()
+		fmt.Println(aurora.Faint(fmt.Sprintf("Locked dependencies: %d", summary.LockedDependencyCount)))
+	}
+
+	printFullyDeprecatedPackages(summary.FullyDeprecated)
+	printBlockedUpdates(summary.Blocked)
+	printHeldPackages(summary.Held)
+	printLockedPackages(summary.Locked)
+	printUnsupportedVersions(summary.Unsupported)
+	printExpiredRules(npm.GetExpiredRules(cfg.Rules))
+	printFetchFailures(summary.Failures)
+
+	fmt.Println()
+
+	return versionComparison, jsonFile, summary, true
+}
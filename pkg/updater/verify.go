@@ -0,0 +1,45 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/icaruk/up-npm/pkg/utils/cli"
+	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
+	"github.com/logrusorgru/aurora/v4"
+)
+
+// runVerifyCommand runs the user configured verification command (e.g. "npm test")
+func runVerifyCommand(command string) error {
+	fmt.Printf("Verifying with '%s'...\n", command)
+
+	if err := cli.RunShellCommand(command); err != nil {
+		return fmt.Errorf("'%s' failed: %w", command, err)
+	}
+
+	return nil
+}
+
+// printBrokenUpdateSet lists the updates that were applied when the verification failed
+func printBrokenUpdateSet(versionComparison map[string]versionpkg.VersionComparisonItem) {
+
+	var names []string
+	for key, value := range versionComparison {
+		if value.ShouldUpdate {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Println(aurora.Red("Verification failed with the following updates:"))
+
+	for _, name := range names {
+		value := versionComparison[name]
+		fmt.Printf(
+			"  %s %s → %s\n",
+			name,
+			value.Current,
+			versionpkg.ColorizeVersion(value.Latest, value.VersionType),
+		)
+	}
+}
@@ -0,0 +1,20 @@
+package advisory
+
+import (
+	"strings"
+
+	"github.com/logrusorgru/aurora/v4"
+)
+
+func ColorizeSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return aurora.Bold(aurora.Red(severity)).String()
+	case "high":
+		return aurora.Red(severity).String()
+	case "moderate":
+		return aurora.Yellow(severity).String()
+	}
+
+	return aurora.Faint(severity).String()
+}
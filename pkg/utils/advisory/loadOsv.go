@@ -0,0 +1,219 @@
+package advisory
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// https://ossf.github.io/osv-schema/
+type osvRange struct {
+	Type   string              `json:"type"`
+	Events []map[string]string `json:"events"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvRecord struct {
+	ID         string `json:"id"`
+	Summary    string `json:"summary"`
+	Withdrawn  string `json:"withdrawn"`
+	References []struct {
+		Type string `json:"type"`
+		Url  string `json:"url"`
+	} `json:"references"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+/*
+Load an OSV database for the npm ecosystem from a directory of .json files or a .zip like
+https://osv-vulnerabilities.storage.googleapis.com/npm/all.zip
+
+Returns the advisories by package name, like the registry bulk endpoint does
+*/
+func LoadOSV(path string) (map[string][]Advisory, error) {
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := map[string][]Advisory{}
+
+	if info.IsDir() {
+		err = filepath.WalkDir(path, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+				return nil
+			}
+
+			file, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			return addOSVRecord(advisories, file, filePath)
+		})
+
+		return advisories, err
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a directory nor a zip file: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, zipFile := range archive.File {
+		if zipFile.FileInfo().IsDir() || !strings.HasSuffix(zipFile.Name, ".json") {
+			continue
+		}
+
+		file, err := zipFile.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		err = addOSVRecord(advisories, file, zipFile.Name)
+		file.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return advisories, nil
+}
+
+func addOSVRecord(advisories map[string][]Advisory, reader io.Reader, name string) error {
+
+	var record osvRecord
+	if err := json.NewDecoder(reader).Decode(&record); err != nil {
+		return fmt.Errorf("invalid OSV record %s: %w", name, err)
+	}
+
+	if record.Withdrawn != "" {
+		return nil
+	}
+
+	for _, affected := range record.Affected {
+		if affected.Package.Ecosystem != "npm" {
+			continue
+		}
+
+		vulnerableVersions := getOSVVulnerableVersions(affected.Ranges, affected.Versions)
+		if vulnerableVersions == "" {
+			continue
+		}
+
+		advisories[affected.Package.Name] = append(advisories[affected.Package.Name], Advisory{
+			ID:                 record.ID,
+			Title:              getOSVTitle(record),
+			Severity:           getOSVSeverity(record),
+			Url:                getOSVUrl(record),
+			VulnerableVersions: vulnerableVersions,
+		})
+	}
+
+	return nil
+}
+
+// getOSVVulnerableVersions converts OSV range events into a semver range like ">=1.0.0 <1.2.3 || =2.0.0"
+func getOSVVulnerableVersions(ranges []osvRange, versions []string) string {
+
+	var parts []string
+
+	for _, r := range ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+
+		introduced := ""
+
+		for _, event := range r.Events {
+			if v, ok := event["introduced"]; ok {
+				if introduced != "" {
+					parts = append(parts, fmt.Sprintf(">=%s", introduced))
+				}
+				introduced = normalizeOSVVersion(v)
+				continue
+			}
+
+			if introduced == "" {
+				continue
+			}
+
+			if v, ok := event["fixed"]; ok {
+				parts = append(parts, fmt.Sprintf(">=%s <%s", introduced, v))
+				introduced = ""
+			} else if v, ok := event["last_affected"]; ok {
+				parts = append(parts, fmt.Sprintf(">=%s <=%s", introduced, v))
+				introduced = ""
+			}
+		}
+
+		// Still affected
+		if introduced != "" {
+			parts = append(parts, fmt.Sprintf(">=%s", introduced))
+		}
+	}
+
+	// Explicit versions are only needed when there are no ranges
+	if len(parts) == 0 {
+		for _, v := range versions {
+			parts = append(parts, fmt.Sprintf("=%s", v))
+		}
+	}
+
+	return strings.Join(parts, " || ")
+}
+
+func normalizeOSVVersion(version string) string {
+	if version == "0" {
+		return "0.0.0-0"
+	}
+	return version
+}
+
+func getOSVTitle(record osvRecord) string {
+	if record.Summary != "" {
+		return record.Summary
+	}
+	return record.ID
+}
+
+func getOSVSeverity(record osvRecord) string {
+	if record.DatabaseSpecific.Severity != "" {
+		return strings.ToLower(record.DatabaseSpecific.Severity)
+	}
+	return "unknown"
+}
+
+func getOSVUrl(record osvRecord) string {
+	for _, referenceType := range []string{"ADVISORY", "WEB"} {
+		for _, reference := range record.References {
+			if reference.Type == referenceType {
+				return reference.Url
+			}
+		}
+	}
+	return fmt.Sprintf("https://osv.dev/vulnerability/%s", record.ID)
+}
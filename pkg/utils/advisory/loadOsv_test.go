@@ -0,0 +1,143 @@
+package advisory
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const osvLodash = `{
+	"id": "GHSA-35jh-r3h4-6jhm",
+	"summary": "Command Injection in lodash",
+	"references": [
+		{"type": "WEB", "url": "https://github.com/lodash/lodash/pull/5085"},
+		{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}
+	],
+	"affected": [
+		{
+			"package": {"ecosystem": "npm", "name": "lodash"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+		},
+		{
+			"package": {"ecosystem": "PyPI", "name": "lodash"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.0.0"}]}]
+		}
+	],
+	"database_specific": {"severity": "HIGH"}
+}`
+
+const osvMultipleRanges = `{
+	"id": "GHSA-xxxx-yyyy-zzzz",
+	"affected": [
+		{
+			"package": {"ecosystem": "npm", "name": "example"},
+			"ranges": [{"type": "SEMVER", "events": [
+				{"introduced": "1.0.0"}, {"fixed": "1.2.3"},
+				{"introduced": "2.0.0"}, {"last_affected": "2.0.5"},
+				{"introduced": "3.0.0"}
+			]}]
+		}
+	]
+}`
+
+const osvWithdrawn = `{
+	"id": "GHSA-withdrawn",
+	"withdrawn": "2024-01-01T00:00:00Z",
+	"affected": [
+		{
+			"package": {"ecosystem": "npm", "name": "example"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+		}
+	]
+}`
+
+func checkOSVAdvisories(t *testing.T, advisories map[string][]Advisory) {
+	t.Helper()
+
+	if len(advisories["lodash"]) != 1 {
+		t.Fatalf("expected 1 lodash advisory but got %d", len(advisories["lodash"]))
+	}
+
+	lodash := advisories["lodash"][0]
+	expected := Advisory{
+		ID:                 "GHSA-35jh-r3h4-6jhm",
+		Title:              "Command Injection in lodash",
+		Severity:           "high",
+		Url:                "https://nvd.nist.gov/vuln/detail/CVE-2021-23337",
+		VulnerableVersions: ">=0.0.0-0 <4.17.21",
+	}
+	if lodash != expected {
+		t.Errorf("expected %+v but got %+v", expected, lodash)
+	}
+
+	if len(advisories["example"]) != 1 {
+		t.Fatalf("expected 1 example advisory but got %d", len(advisories["example"]))
+	}
+
+	example := advisories["example"][0]
+	expectedRange := ">=1.0.0 <1.2.3 || >=2.0.0 <=2.0.5 || >=3.0.0"
+	if example.VulnerableVersions != expectedRange {
+		t.Errorf("expected range %v but got %v", expectedRange, example.VulnerableVersions)
+	}
+	if example.Title != "GHSA-xxxx-yyyy-zzzz" || example.Severity != "unknown" || example.Url != "https://osv.dev/vulnerability/GHSA-xxxx-yyyy-zzzz" {
+		t.Errorf("unexpected advisory %+v", example)
+	}
+}
+
+func TestLoadOSVDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"GHSA-35jh-r3h4-6jhm.json": osvLodash,
+		"nested/GHSA-xxxx.json":    osvMultipleRanges,
+		"GHSA-withdrawn.json":      osvWithdrawn,
+		"README.md":                "not an advisory",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	advisories, err := LoadOSV(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkOSVAdvisories(t, advisories)
+}
+
+func TestLoadOSVZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "all.zip")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"GHSA-35jh-r3h4-6jhm.json": osvLodash,
+		"GHSA-xxxx.json":           osvMultipleRanges,
+		"GHSA-withdrawn.json":      osvWithdrawn,
+	} {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	writer.Close()
+	file.Close()
+
+	advisories, err := LoadOSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkOSVAdvisories(t, advisories)
+}
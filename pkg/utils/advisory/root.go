@@ -0,0 +1,109 @@
+package advisory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type Advisory struct {
+	ID       string
+	Title    string
+	Severity string // critical, high, moderate, low or info
+	Url      string
+	// semver range of the affected versions like "<4.17.21"
+	VulnerableVersions string
+}
+
+var severityRanks = map[string]int{
+	"critical": 4,
+	"high":     3,
+	"moderate": 2,
+	"low":      1,
+	"info":     0,
+}
+
+// SeverityRank returns a comparable rank for a severity, higher is worse
+func SeverityRank(severity string) int {
+	return severityRanks[strings.ToLower(severity)]
+}
+
+// HighestSeverity returns the worst severity of the advisories or "" if there are none
+func HighestSeverity(advisories []Advisory) string {
+	highest := ""
+	for _, advisory := range advisories {
+		if highest == "" || SeverityRank(advisory.Severity) > SeverityRank(highest) {
+			highest = advisory.Severity
+		}
+	}
+	return highest
+}
+
+type bulkAdvisory struct {
+	ID                 json.Number `json:"id"`
+	Url                string      `json:"url"`
+	Title              string      `json:"title"`
+	Severity           string      `json:"severity"`
+	VulnerableVersions string      `json:"vulnerable_versions"`
+}
+
+/*
+Fetch the advisories affecting the given package versions using the npm bulk advisory endpoint
+
+packages is like {"lodash": ["4.17.20"]}
+*/
+func FetchBulkAdvisories(client *http.Client, registry string, token string, packages map[string][]string) (map[string][]Advisory, error) {
+
+	body, err := json.Marshal(packages)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/-/npm/v1/security/advisories/bulk", strings.TrimSuffix(registry, "/"))
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if token != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("advisories, status code: %d", resp.StatusCode)
+	}
+
+	var result map[string][]bulkAdvisory
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := map[string][]Advisory{}
+
+	for name, items := range result {
+		for _, item := range items {
+			advisories[name] = append(advisories[name], Advisory{
+				ID:                 item.ID.String(),
+				Title:              item.Title,
+				Severity:           item.Severity,
+				Url:                item.Url,
+				VulnerableVersions: item.VulnerableVersions,
+			})
+		}
+	}
+
+	return advisories, nil
+}
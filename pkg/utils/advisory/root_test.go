@@ -0,0 +1,76 @@
+package advisory
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchBulkAdvisories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/-/npm/v1/security/advisories/bulk" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var packages map[string][]string
+		if err := json.NewDecoder(r.Body).Decode(&packages); err != nil || packages["lodash"][0] != "4.17.20" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Write([]byte(`{
+			"lodash": [
+				{
+					"id": 1096366,
+					"url": "https://github.com/advisories/GHSA-35jh-r3h4-6jhm",
+					"title": "Command Injection in lodash",
+					"severity": "high",
+					"vulnerable_versions": "<4.17.21"
+				}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	advisories, err := FetchBulkAdvisories(server.Client(), server.URL+"/", "", map[string][]string{"lodash": {"4.17.20"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(advisories["lodash"]) != 1 {
+		t.Fatalf("expected 1 advisory but got %d", len(advisories["lodash"]))
+	}
+
+	a := advisories["lodash"][0]
+	if a.ID != "1096366" || a.Severity != "high" || a.VulnerableVersions != "<4.17.21" {
+		t.Errorf("unexpected advisory %+v", a)
+	}
+}
+
+func TestHighestSeverity(t *testing.T) {
+	testCases := []struct {
+		name       string
+		severities []string
+		expected   string
+	}{
+		{name: "none", severities: []string{}, expected: ""},
+		{name: "single", severities: []string{"low"}, expected: "low"},
+		{name: "mixed", severities: []string{"moderate", "critical", "high"}, expected: "critical"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var advisories []Advisory
+			for _, severity := range tc.severities {
+				advisories = append(advisories, Advisory{Severity: severity})
+			}
+
+			highest := HighestSeverity(advisories)
+			if highest != tc.expected {
+				t.Errorf("expected %v but got %v", tc.expected, highest)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package bisect
+
+// Bisect applies the candidates in halves until every failing candidate is isolated.
+//
+// test receives the set of candidates to apply and reports whether it passes.
+// accepted is the biggest set found that passes, culprits are the candidates that fail on their own
+// on top of the accepted ones.
+func Bisect[T any](candidates []T, test func(applied []T) bool) (accepted []T, culprits []T) {
+	return search([]T{}, candidates, test)
+}
+
+func search[T any](accepted []T, candidates []T, test func(applied []T) bool) ([]T, []T) {
+
+	if len(candidates) == 0 {
+		return accepted, nil
+	}
+
+	applied := append(append([]T{}, accepted...), candidates...)
+
+	if test(applied) {
+		return applied, nil
+	}
+
+	if len(candidates) == 1 {
+		return accepted, candidates
+	}
+
+	half := len(candidates) / 2
+
+	accepted, leftCulprits := search(accepted, candidates[:half], test)
+	accepted, rightCulprits := search(accepted, candidates[half:], test)
+
+	return accepted, append(leftCulprits, rightCulprits...)
+}
@@ -0,0 +1,73 @@
+package bisect
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestBisect(t *testing.T) {
+	testCases := []struct {
+		name             string
+		candidates       []string
+		broken           []string
+		expectedAccepted []string
+		expectedCulprits []string
+	}{
+		{
+			name:             "all pass",
+			candidates:       []string{"a", "b", "c", "d"},
+			broken:           []string{},
+			expectedAccepted: []string{"a", "b", "c", "d"},
+			expectedCulprits: nil,
+		},
+		{
+			name:             "single culprit",
+			candidates:       []string{"a", "b", "c", "d", "e"},
+			broken:           []string{"d"},
+			expectedAccepted: []string{"a", "b", "c", "e"},
+			expectedCulprits: []string{"d"},
+		},
+		{
+			name:             "two culprits",
+			candidates:       []string{"a", "b", "c", "d", "e", "f", "g", "h"},
+			broken:           []string{"a", "g"},
+			expectedAccepted: []string{"b", "c", "d", "e", "f", "h"},
+			expectedCulprits: []string{"a", "g"},
+		},
+		{
+			name:             "all fail",
+			candidates:       []string{"a", "b"},
+			broken:           []string{"a", "b"},
+			expectedAccepted: []string{},
+			expectedCulprits: []string{"a", "b"},
+		},
+		{
+			name:             "no candidates",
+			candidates:       []string{},
+			broken:           []string{},
+			expectedAccepted: []string{},
+			expectedCulprits: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accepted, culprits := Bisect(tc.candidates, func(applied []string) bool {
+				for _, b := range tc.broken {
+					if slices.Contains(applied, b) {
+						return false
+					}
+				}
+				return true
+			})
+
+			if !reflect.DeepEqual(accepted, tc.expectedAccepted) {
+				t.Errorf("expected accepted %v but got %v", tc.expectedAccepted, accepted)
+			}
+			if !reflect.DeepEqual(culprits, tc.expectedCulprits) {
+				t.Errorf("expected culprits %v but got %v", tc.expectedCulprits, culprits)
+			}
+		})
+	}
+}
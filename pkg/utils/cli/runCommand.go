@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RunCommand executes a command like "npm install" piping its output to the terminal
+func RunCommand(command string) error {
+
+	// Split command and args
+	commandAndArgs := strings.Fields(command)
+	if len(commandAndArgs) == 0 {
+		return errors.New("empty command")
+	}
+
+	name := commandAndArgs[0]
+	args := commandAndArgs[1:]
+
+	cmd := exec.Command(name, args...)
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
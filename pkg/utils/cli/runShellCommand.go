@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// RunShellCommand executes a user provided command line like "npm test -- --run" through the system shell
+func RunShellCommand(command string) error {
+
+	var cmd *exec.Cmd
+
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
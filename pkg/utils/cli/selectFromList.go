@@ -5,22 +5,34 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/icaruk/up-npm/pkg/utils/advisory"
+	"github.com/icaruk/up-npm/pkg/utils/license"
 	versionpkg "github.com/icaruk/up-npm/pkg/utils/version"
 	"github.com/logrusorgru/aurora/v4"
 )
 
 type SelectUpdateOptions struct {
-	update       string
-	skip         string
-	show_changes string
-	finish       string
+	update                 string
+	update_node_compatible string
+	skip                   string
+	show_changes           string
+	finish                 string
 }
 
 var SelectUpdateAvailableOptions = SelectUpdateOptions{
-	update:       "Update",
-	skip:         "Skip",
-	show_changes: "Show changes",
-	finish:       "Finish",
+	update:                 "Update",
+	update_node_compatible: "Update to Node compatible version",
+	skip:                   "Skip",
+	show_changes:           "Show changes",
+	finish:                 "Finish",
+}
+
+// formatProgress returns "[current/max]", "[current/?]" if max is unknown because packages are still loading
+func formatProgress(currentCount int, maxCount int) string {
+	if maxCount <= 0 {
+		return fmt.Sprintf("[%d/?]", currentCount)
+	}
+	return fmt.Sprintf("[%d/%d]", currentCount, maxCount)
 }
 
 func PromptUpdateDependency(
@@ -35,8 +47,12 @@ func PromptUpdateDependency(
 	lockedVersionWarning := ""
 	tooRecentReleaseWarning := ""
 
-	if versionComparisonItem.VersionPrefix == "" {
-		lockedVersionWarning = aurora.Sprintf("\n%s", aurora.Faint("version is locked"))
+	if versionComparisonItem.IsLocked {
+		lockedSt := "version is locked"
+		if versionComparisonItem.VersionPrefix != "" {
+			lockedSt = fmt.Sprintf("version is locked, the update is written with \"%s\"", versionComparisonItem.VersionPrefix)
+		}
+		lockedVersionWarning = aurora.Sprintf("\n%s", aurora.Faint(lockedSt))
 	}
 
 	if versionComparisonItem.HoursSinceLasRelease > 0 && versionComparisonItem.HoursSinceLasRelease < 24 {
@@ -49,6 +65,109 @@ func PromptUpdateDependency(
 		)
 	}
 
+	advisoriesWarning := ""
+
+	for _, a := range versionComparisonItem.Advisories {
+		fixedSt := aurora.Green("fixed in latest").String()
+		if !versionpkg.IsFixedBy(a, versionComparisonItem.Latest) {
+			fixedSt = aurora.Red("NOT fixed in latest").String()
+		}
+
+		advisoriesWarning += aurora.Sprintf(
+			"\n%s %s: %s (%s)",
+			aurora.Bold(aurora.Red("VULNERABLE:")),
+			advisory.ColorizeSeverity(a.Severity),
+			a.Title,
+			fixedSt,
+		)
+	}
+
+	deprecationWarning := ""
+
+	if versionComparisonItem.CurrentDeprecated != "" {
+		deprecationWarning += aurora.Sprintf(
+			"\n%s %s",
+			aurora.Yellow("Your current version is deprecated:"),
+			versionComparisonItem.CurrentDeprecated,
+		)
+	}
+
+	if versionComparisonItem.LatestDeprecated != "" {
+		deprecationWarning += aurora.Sprintf(
+			"\n%s %s",
+			aurora.Bold(aurora.Red("WARNING: latest version is deprecated:")),
+			versionComparisonItem.LatestDeprecated,
+		)
+
+		if versionComparisonItem.SuggestedVersion != "" {
+			deprecationWarning += aurora.Sprintf(
+				"\nNewest non-deprecated version is %s",
+				aurora.Green(versionComparisonItem.SuggestedVersion),
+			)
+		}
+	}
+
+	risksWarning := ""
+
+	for _, risk := range versionComparisonItem.Risks {
+		risksWarning += aurora.Sprintf(
+			"\n%s %s",
+			aurora.Bold(aurora.Magenta("RISK:")),
+			risk,
+		)
+	}
+
+	if versionComparisonItem.SignatureError != "" {
+		risksWarning += aurora.Sprintf(
+			"\n%s %s",
+			aurora.Bold(aurora.Red("SIGNATURE:")),
+			versionComparisonItem.SignatureError,
+		)
+	}
+
+	licenseWarning := ""
+
+	if versionComparisonItem.LicenseChanged() {
+		licenseWarning = aurora.Sprintf(
+			"\n%s changed from %s to %s",
+			aurora.Bold(aurora.Yellow("LICENSE:")),
+			license.Format(versionComparisonItem.CurrentLicense),
+			aurora.Bold(license.Format(versionComparisonItem.LatestLicense)),
+		)
+	}
+
+	nodeWarning := ""
+
+	if versionComparisonItem.RequiredNode != "" {
+		nodeWarning = aurora.Sprintf(
+			"\n%s latest version requires node %s",
+			aurora.Bold(aurora.Yellow("NODE:")),
+			versionComparisonItem.RequiredNode,
+		)
+
+		if versionComparisonItem.NodeCompatibleVersion != "" {
+			nodeWarning += aurora.Sprintf(
+				", newest version supporting your node is %s",
+				aurora.Green(versionComparisonItem.NodeCompatibleVersion),
+			)
+		}
+	}
+
+	options := []huh.Option[string]{
+		huh.NewOption(SelectUpdateAvailableOptions.update, SelectUpdateAvailableOptions.update),
+	}
+	if versionComparisonItem.NodeCompatibleVersion != "" {
+		options = append(options, huh.NewOption(
+			fmt.Sprintf("Update to %s", versionComparisonItem.NodeCompatibleVersion),
+			SelectUpdateAvailableOptions.update_node_compatible,
+		))
+	}
+	options = append(options,
+		huh.NewOption(SelectUpdateAvailableOptions.skip, SelectUpdateAvailableOptions.skip),
+		huh.NewOption(SelectUpdateAvailableOptions.show_changes, SelectUpdateAvailableOptions.show_changes),
+		huh.NewOption(SelectUpdateAvailableOptions.finish, SelectUpdateAvailableOptions.finish),
+	)
+
 	selectForm := huh.NewSelect[string]().
 		Title(
 			lipgloss.NewStyle().
@@ -56,14 +175,72 @@ func PromptUpdateDependency(
 				PaddingTop(1).
 				Render(
 					fmt.Sprintf(
-						"[%d/%d] Update \"%s\" from %s to %s?%s%s",
-						currentCount,
-						maxCount,
+						"%s Update \"%s\" from %s to %s?%s%s%s%s%s%s%s",
+						formatProgress(currentCount, maxCount),
 						dependencyName,
 						versionComparisonItem.Current,
 						versionpkg.ColorizeVersion(versionComparisonItem.Latest, versionComparisonItem.VersionType),
 						lockedVersionWarning,
 						tooRecentReleaseWarning,
+						advisoriesWarning,
+						deprecationWarning,
+						risksWarning,
+						licenseWarning,
+						nodeWarning,
+					),
+				),
+		).
+		Options(options...).
+		Value(&selected).
+		WithTheme(huh.ThemeBase16())
+
+	selectForm.Run()
+
+	return selected
+}
+
+// PromptUpdateGroup asks once for every member of an update group
+func PromptUpdateGroup(
+	groupName string,
+	members []versionpkg.PackageVersion,
+	currentCount int,
+	maxCount int,
+) string {
+
+	var selected string
+
+	membersSt := ""
+	for _, member := range members {
+		membersSt += fmt.Sprintf(
+			"\n  %s %s → %s",
+			member.Name,
+			member.Current,
+			versionpkg.ColorizeVersion(member.Latest, member.VersionType),
+		)
+
+		if member.IsVulnerable() {
+			membersSt += aurora.Sprintf(" %s", aurora.Red("(vulnerable)"))
+		}
+		if member.LatestDeprecated != "" {
+			membersSt += aurora.Sprintf(" %s", aurora.Red("(latest deprecated)"))
+		}
+		if member.RequiredNode != "" {
+			membersSt += aurora.Sprintf(aurora.Yellow(" (requires node %s)"), member.RequiredNode)
+		}
+	}
+
+	selectForm := huh.NewSelect[string]().
+		Title(
+			lipgloss.NewStyle().
+				Foreground(lipgloss.Color("7")). // white
+				PaddingTop(1).
+				Render(
+					fmt.Sprintf(
+						"%s Update group \"%s\" (%d packages)?%s",
+						formatProgress(currentCount, maxCount),
+						groupName,
+						len(members),
+						membersSt,
 					),
 				),
 		).
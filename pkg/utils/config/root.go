@@ -0,0 +1,254 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files looked up on every directory, in order of preference, JSON only. package.json is only used if it has the PackageJsonKey
+var Filenames = []string{".up-npmrc.json", "up-npm.config.json"}
+
+const PackageJsonKey = "up-npm"
+
+/*
+Config is the project configuration, like:
+
+	{
+		"no-dev": true,
+		"install": "targeted",
+		"ignore": ["typescript", "@types/*"],
+		"ceilings": {"react": "^18"},
+		"groups": {"nestjs": ["@nestjs/*"]},
+		"registries": {"@mycompany": "https://npm.mycompany.com"},
+		"rules": [{"name": "typescript", "maxVersion": "5.4.x", "reason": "angular compat", "until": "2026-12-01"}]
+	}
+
+Every other key is the default value of the flag with the same name.
+*/
+type Config struct {
+	// File the config was read from, "" if there is none
+	Path string
+	// Flag name => value
+	Flags map[string]any
+	// Packages never checked, globs or scopes like the groups
+	Ignore []string
+	// Package => highest range allowed, like "^18"
+	Ceilings map[string]string
+	// Group name => patterns
+	Groups map[string][]string
+	// Scope => registry
+	Registries map[string]string
+	// Packages held back or ignored, with a reason
+	Rules []Rule
+}
+
+/*
+Rule holds back the packages matching Name (a name, glob or scope) to MaxVersion, like
+{"name": "typescript", "maxVersion": "5.4.x", "reason": "angular compat", "until": "2026-12-01"}.
+
+Without MaxVersion the packages are ignored. Rules stop applying on the Until date (YYYY-MM-DD).
+*/
+type Rule struct {
+	Name       string `json:"name"`
+	MaxVersion string `json:"maxVersion"`
+	Reason     string `json:"reason"`
+	Until      string `json:"until"`
+}
+
+const RuleDateLayout = "2006-01-02"
+
+// IsExpired checks if the Until date has been reached
+func (r Rule) IsExpired(now time.Time) bool {
+	if r.Until == "" {
+		return false
+	}
+
+	until, err := time.ParseInLocation(RuleDateLayout, r.Until, now.Location())
+	if err != nil {
+		return false
+	}
+
+	return !now.Before(until)
+}
+
+func (r Rule) String() string {
+	st := r.Name
+	if r.MaxVersion != "" {
+		st += " <= " + r.MaxVersion
+	} else {
+		st += " ignored"
+	}
+	if r.Reason != "" {
+		st += ": " + r.Reason
+	}
+	if r.Until != "" {
+		st += fmt.Sprintf(" (until %s)", r.Until)
+	}
+	return st
+}
+
+// Parse reads a config file content
+func Parse(data []byte) (Config, error) {
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Config{}, err
+	}
+
+	config := Config{Flags: map[string]any{}}
+
+	fields := map[string]any{
+		"ignore":     &config.Ignore,
+		"ceilings":   &config.Ceilings,
+		"groups":     &config.Groups,
+		"registries": &config.Registries,
+		"rules":      &config.Rules,
+	}
+
+	for key, value := range raw {
+		if field, ok := fields[key]; ok {
+			if err := json.Unmarshal(value, field); err != nil {
+				return Config{}, fmt.Errorf("invalid \"%s\": %w", key, err)
+			}
+			continue
+		}
+
+		var flagValue any
+		if err := json.Unmarshal(value, &flagValue); err != nil {
+			return Config{}, fmt.Errorf("invalid \"%s\": %w", key, err)
+		}
+		config.Flags[key] = flagValue
+	}
+
+	for _, rule := range config.Rules {
+		if rule.Name == "" {
+			return Config{}, fmt.Errorf("rule without name")
+		}
+		if rule.Until != "" {
+			if _, err := time.Parse(RuleDateLayout, rule.Until); err != nil {
+				return Config{}, fmt.Errorf("rule \"%s\": invalid until date \"%s\", use YYYY-MM-DD", rule.Name, rule.Until)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// Find looks for the config on dir and its parents, the closest one wins
+func Find(dir string) (Config, error) {
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Config{}, err
+	}
+
+	for {
+		config, found, err := readDir(dir)
+		if err != nil || found {
+			return config, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Config{}, nil
+		}
+		dir = parent
+	}
+}
+
+func readDir(dir string) (config Config, found bool, err error) {
+
+	for _, filename := range Filenames {
+		path := filepath.Join(dir, filename)
+
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return Config{}, false, err
+		}
+
+		config, err := Parse(data)
+		if err != nil {
+			return Config{}, false, fmt.Errorf("%s: %w", path, err)
+		}
+
+		config.Path = path
+		return config, true, nil
+	}
+
+	path := filepath.Join(dir, "package.json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, false, nil
+	}
+
+	var packageJson map[string]json.RawMessage
+	if err := json.Unmarshal(data, &packageJson); err != nil {
+		return Config{}, false, nil
+	}
+
+	section, ok := packageJson[PackageJsonKey]
+	if !ok {
+		return Config{}, false, nil
+	}
+
+	config, err = Parse(section)
+	if err != nil {
+		return Config{}, false, fmt.Errorf("%s \"%s\": %w", path, PackageJsonKey, err)
+	}
+
+	config.Path = path
+	return config, true, nil
+}
+
+// GroupDefinitions returns the groups like the --group flag values, "name=pattern,pattern"
+func (c Config) GroupDefinitions() []string {
+
+	names := make([]string, 0, len(c.Groups))
+	for name := range c.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var definitions []string
+	for _, name := range names {
+		definitions = append(definitions, fmt.Sprintf("%s=%s", name, strings.Join(c.Groups[name], ",")))
+	}
+
+	return definitions
+}
+
+// FlagValues converts a config value to the strings that would be passed to the flag, one per item for lists
+func FlagValues(value any) ([]string, error) {
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case float64:
+		// fmt.Sprint would give "1e+06" for big numbers, which the flags can't parse
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []any:
+		var values []string
+		for _, item := range v {
+			itemValues, err := FlagValues(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, itemValues...)
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %v", value)
+}
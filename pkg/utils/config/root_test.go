@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(`{
+		"no-dev": true,
+		"install": "targeted",
+		"group": ["a=a,b"],
+		"ignore": ["typescript", "@types/*"],
+		"ceilings": {"react": "^18"},
+		"groups": {"nestjs": ["@nestjs/*"], "babel": ["@babel"]},
+		"registries": {"@mycompany": "https://npm.mycompany.com"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		Flags: map[string]any{
+			"no-dev":  true,
+			"install": "targeted",
+			"group":   []any{"a=a,b"},
+		},
+		Ignore:     []string{"typescript", "@types/*"},
+		Ceilings:   map[string]string{"react": "^18"},
+		Groups:     map[string][]string{"nestjs": {"@nestjs/*"}, "babel": {"@babel"}},
+		Registries: map[string]string{"@mycompany": "https://npm.mycompany.com"},
+	}
+
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected %+v but got %+v", expected, config)
+	}
+
+	if definitions := config.GroupDefinitions(); !reflect.DeepEqual(definitions, []string{"babel=@babel", "nestjs=@nestjs/*"}) {
+		t.Errorf("GroupDefinitions() = %v", definitions)
+	}
+
+	if _, err := Parse([]byte(`{"ignore": "typescript"}`)); err == nil {
+		t.Error("expected error for invalid ignore")
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "packages", "app")
+
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(path string, content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// package.json without "up-npm" key is skipped
+	write(filepath.Join(project, "package.json"), `{"name": "app"}`)
+	write(filepath.Join(root, "package.json"), `{"name": "root", "up-npm": {"no-dev": true}}`)
+
+	config, err := Find(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Path != filepath.Join(root, "package.json") || config.Flags["no-dev"] != true {
+		t.Errorf("expected the root package.json config but got %+v", config)
+	}
+
+	// The closest file wins and config files go before package.json
+	write(filepath.Join(root, "packages", ".up-npmrc.json"), `{"install": "full"}`)
+	write(filepath.Join(root, "packages", "package.json"), `{"up-npm": {"install": "none"}}`)
+
+	config, err = Find(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Path != filepath.Join(root, "packages", ".up-npmrc.json") || config.Flags["install"] != "full" {
+		t.Errorf("expected packages/.up-npmrc.json but got %+v", config)
+	}
+}
+
+func TestFlagValues(t *testing.T) {
+	testCases := []struct {
+		value    any
+		expected []string
+	}{
+		{value: "targeted", expected: []string{"targeted"}},
+		{value: true, expected: []string{"true"}},
+		{value: float64(10), expected: []string{"10"}},
+		{value: float64(1000000), expected: []string{"1000000"}},
+		{value: float64(0.5), expected: []string{"0.5"}},
+		{value: []any{"MIT", "ISC"}, expected: []string{"MIT", "ISC"}},
+	}
+
+	for _, tc := range testCases {
+		values, err := FlagValues(tc.value)
+		if err != nil || !reflect.DeepEqual(values, tc.expected) {
+			t.Errorf("expected %v but got %v, %v for %v", tc.expected, values, err, tc.value)
+		}
+	}
+
+	if _, err := FlagValues(map[string]any{}); err == nil {
+		t.Error("expected error for objects")
+	}
+}
+
+func TestRules(t *testing.T) {
+	config, err := Parse([]byte(`{
+		"rules": [
+			{"name": "typescript", "maxVersion": "5.4.x", "reason": "angular compat", "until": "2026-12-01"},
+			{"name": "@types/*"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule := config.Rules[0]
+	if rule.String() != "typescript <= 5.4.x: angular compat (until 2026-12-01)" {
+		t.Errorf("String() = %q", rule.String())
+	}
+
+	before := time.Date(2026, 11, 30, 23, 0, 0, 0, time.UTC)
+	after := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	if rule.IsExpired(before) || !rule.IsExpired(after) {
+		t.Errorf("IsExpired() wrong around %s", rule.Until)
+	}
+	if config.Rules[1].IsExpired(after) {
+		t.Error("rules without until never expire")
+	}
+
+	if _, err := Parse([]byte(`{"rules": [{"name": "a", "until": "01/12/2026"}]}`)); err == nil {
+		t.Error("expected error for invalid until date")
+	}
+}
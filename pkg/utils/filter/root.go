@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/group"
+)
+
+// Pattern matches package names with a glob ("@types/*"), a scope ("@babel") or a regex ("/^eslint/")
+type Pattern struct {
+	raw   string
+	regex *regexp.Regexp
+}
+
+func Compile(pattern string) (Pattern, error) {
+
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		regex, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return Pattern{}, fmt.Errorf("invalid regex \"%s\": %w", pattern, err)
+		}
+		return Pattern{raw: pattern, regex: regex}, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return Pattern{}, fmt.Errorf("invalid pattern \"%s\": %w", pattern, err)
+	}
+
+	return Pattern{raw: pattern}, nil
+}
+
+func (p Pattern) Match(name string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(name)
+	}
+	return group.MatchPattern(p.raw, name)
+}
+
+// Filter decides which packages are checked, exclude patterns win over include ones
+type Filter struct {
+	Include []Pattern
+	Exclude []Pattern
+}
+
+func New(include []string, exclude []string) (Filter, error) {
+
+	var f Filter
+
+	for _, pattern := range include {
+		p, err := Compile(pattern)
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Include = append(f.Include, p)
+	}
+
+	for _, pattern := range exclude {
+		p, err := Compile(pattern)
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Exclude = append(f.Exclude, p)
+	}
+
+	return f, nil
+}
+
+// Allows checks if a package is not excluded and matches some include pattern, if there are any
+func (f Filter) Allows(name string) bool {
+
+	for _, p := range f.Exclude {
+		if p.Match(name) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+
+	for _, p := range f.Include {
+		if p.Match(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEmpty checks if the filter has no patterns
+func (f Filter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
@@ -0,0 +1,56 @@
+package filter
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	f, err := New(
+		[]string{"react", "@types/*", "/^eslint/", "@babel"},
+		[]string{"@types/node", "/plugin-react$/"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "react", expected: true},
+		{name: "preact", expected: false},
+		{name: "react-native-svg", expected: false},
+		{name: "@types/react", expected: true},
+		{name: "@types/node", expected: false},
+		{name: "eslint", expected: true},
+		{name: "eslint-config-next", expected: true},
+		{name: "eslint-plugin-react", expected: false},
+		{name: "@babel/core", expected: true},
+		{name: "lodash", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if allows := f.Allows(tc.name); allows != tc.expected {
+				t.Errorf("expected %v but got %v for %q", tc.expected, allows, tc.name)
+			}
+		})
+	}
+}
+
+func TestFilterOnlyExclude(t *testing.T) {
+	f, err := New(nil, []string{"@types"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !f.Allows("lodash") || f.Allows("@types/node") {
+		t.Error("only exclude patterns should allow everything else")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, pattern := range []string{"/[/", "["} {
+		if _, err := Compile(pattern); err == nil {
+			t.Errorf("Compile(%q) expected error", pattern)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run executes git with the given args and returns its trimmed output
+func run(args ...string) (string, error) {
+	return runIn("", args...)
+}
+
+// runIn executes git inside dir, the current directory if empty
+func runIn(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// IsRepository checks if the current directory is inside a git work tree
+func IsRepository() bool {
+	output, err := run("rev-parse", "--is-inside-work-tree")
+	return err == nil && output == "true"
+}
+
+// HasChanges checks if any of the given files has staged or unstaged changes
+func HasChanges(files ...string) (bool, error) {
+	output, err := run(append([]string{"status", "--porcelain", "--"}, files...)...)
+	if err != nil {
+		return false, err
+	}
+
+	return output != "", nil
+}
+
+// CreateBranch creates a new branch and switches to it
+func CreateBranch(name string) error {
+	_, err := run("checkout", "-b", name)
+	return err
+}
+
+// Commit stages and commits only the given files, anything else already staged is left out
+func Commit(message string, files ...string) error {
+
+	var existingFiles []string
+	for _, file := range files {
+		if isTracked(file) || fileExists(file) {
+			existingFiles = append(existingFiles, file)
+		}
+	}
+
+	if _, err := run(append([]string{"add", "--"}, existingFiles...)...); err != nil {
+		return err
+	}
+
+	_, err := run(append([]string{"commit", "-m", message, "--"}, existingFiles...)...)
+	return err
+}
+
+/*
+Restore discards the changes of the given files that are on HEAD.
+
+Files that are not on HEAD are left as they are and returned, git can't restore them.
+*/
+func Restore(files ...string) (untracked []string, err error) {
+
+	for _, file := range files {
+		if !isTracked(file) {
+			untracked = append(untracked, file)
+			continue
+		}
+
+		// Relative to the directory of the file, it can be outside of the current one
+		if _, err := runIn(filepath.Dir(file), "checkout", "HEAD", "--", filepath.Base(file)); err != nil {
+			return untracked, err
+		}
+	}
+
+	return untracked, nil
+}
+
+// isTracked checks if the file is on HEAD, from the directory of the file so absolute paths work too
+func isTracked(file string) bool {
+
+	dir := filepath.Dir(file)
+
+	// HEAD:<path> needs the path from the root of the repository
+	prefix, err := runIn(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return false
+	}
+
+	_, err = runIn(dir, "cat-file", "-e", fmt.Sprintf("HEAD:%s%s", prefix, filepath.Base(file)))
+	return err == nil
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
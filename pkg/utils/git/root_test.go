@@ -0,0 +1,58 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreAbsolutePath(t *testing.T) {
+
+	// A repository outside of the current directory, with package.json in a subdirectory
+	repository := t.TempDir()
+	dir := filepath.Join(repository, "app")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "package.json")
+	lockfile := filepath.Join(dir, "package-lock.json")
+
+	if err := os.WriteFile(file, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "app/package.json"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := runIn(repository, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(file, []byte("updated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockfile, []byte("lockfile"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	untracked, err := Restore(file, lockfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil || string(content) != "original" {
+		t.Errorf("expected package.json to be restored but got %q, %v", content, err)
+	}
+
+	if len(untracked) != 1 || untracked[0] != lockfile {
+		t.Errorf("expected %v to be untracked but got %v", lockfile, untracked)
+	}
+	if _, err := os.Stat(lockfile); err != nil {
+		t.Errorf("expected the untracked lockfile to be kept but got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package group
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+// Group is a set of packages that must be updated together
+type Group struct {
+	Name     string
+	Patterns []string
+}
+
+/*
+Parse parses a group definition like "nestjs=@nestjs/*" or "react=react,react-dom,@types/react".
+
+Without name the patterns are used as name: "@babel/*".
+*/
+func Parse(definition string) (Group, error) {
+
+	name, patternList, found := strings.Cut(definition, "=")
+	if !found {
+		patternList = definition
+		name = definition
+	}
+
+	var patterns []string
+	for _, pattern := range strings.Split(patternList, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Group{}, fmt.Errorf("invalid group pattern \"%s\": %w", pattern, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	if len(patterns) == 0 {
+		return Group{}, fmt.Errorf("group \"%s\" has no patterns", definition)
+	}
+
+	return Group{Name: strings.TrimSpace(name), Patterns: patterns}, nil
+}
+
+// MatchPattern checks if a package name matches a glob like "@babel/*" or a scope like "@babel"
+func MatchPattern(pattern string, name string) bool {
+
+	if strings.HasPrefix(pattern, "@") && !strings.Contains(pattern, "/") {
+		return strings.HasPrefix(name, pattern+"/")
+	}
+
+	matches, _ := path.Match(pattern, name)
+	return matches
+}
+
+// Matches checks if a package belongs to the group
+func (g Group) Matches(name string) bool {
+	for _, pattern := range g.Patterns {
+		if MatchPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// getScope returns "@babel" for "@babel/core", "" for packages without scope
+func getScope(name string) string {
+	if !strings.HasPrefix(name, "@") {
+		return ""
+	}
+	scope, _, _ := strings.Cut(name, "/")
+	return scope
+}
+
+/*
+Assign sets the Group of every package matching one of the groups, the first matching group wins.
+
+With autoGroup, the remaining packages of the same scope updating to the same version are grouped too,
+like "@babel/core" and "@babel/preset-env" both going to 7.24.0.
+*/
+func Assign(groups []Group, autoGroup bool, versionComparison map[string]version.VersionComparisonItem) {
+
+	names := make([]string, 0, len(versionComparison))
+	for name := range versionComparison {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	autoGroups := map[string][]string{}
+
+	for _, name := range names {
+		item := versionComparison[name]
+		item.Group = ""
+
+		for _, g := range groups {
+			if g.Matches(name) {
+				item.Group = g.Name
+				break
+			}
+		}
+
+		versionComparison[name] = item
+
+		if item.Group == "" && autoGroup {
+			if scope := getScope(name); scope != "" {
+				key := fmt.Sprintf("%s@%s", scope, item.Latest)
+				autoGroups[key] = append(autoGroups[key], name)
+			}
+		}
+	}
+
+	for key, members := range autoGroups {
+		if len(members) < 2 {
+			continue
+		}
+
+		for _, name := range members {
+			item := versionComparison[name]
+			item.Group = key
+			versionComparison[name] = item
+		}
+	}
+}
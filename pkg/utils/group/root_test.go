@@ -0,0 +1,89 @@
+package group
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		definition    string
+		expected      Group
+		expectedError bool
+	}{
+		{definition: "nestjs=@nestjs/*", expected: Group{Name: "nestjs", Patterns: []string{"@nestjs/*"}}},
+		{definition: "react=react, react-dom,@types/react", expected: Group{Name: "react", Patterns: []string{"react", "react-dom", "@types/react"}}},
+		{definition: "@babel", expected: Group{Name: "@babel", Patterns: []string{"@babel"}}},
+		{definition: "broken=[", expectedError: true},
+		{definition: "empty=", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.definition, func(t *testing.T) {
+			g, err := Parse(tc.definition)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %v but got %v", tc.expectedError, err)
+			}
+			if !tc.expectedError && !reflect.DeepEqual(g, tc.expected) {
+				t.Errorf("expected %+v but got %+v", tc.expected, g)
+			}
+		})
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "@nestjs/*", name: "@nestjs/core", expected: true},
+		{pattern: "@nestjs/*", name: "@nestjsx/core", expected: false},
+		{pattern: "@babel", name: "@babel/core", expected: true},
+		{pattern: "@babel", name: "@babelx/core", expected: false},
+		{pattern: "eslint-plugin-*", name: "eslint-plugin-react", expected: true},
+		{pattern: "react", name: "react-dom", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			if matches := MatchPattern(tc.pattern, tc.name); matches != tc.expected {
+				t.Errorf("expected %v but got %v for %q and %q", tc.expected, matches, tc.pattern, tc.name)
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	versionComparison := map[string]version.VersionComparisonItem{
+		"react":             {Latest: "19.0.0"},
+		"react-dom":         {Latest: "19.0.0"},
+		"@types/react":      {Latest: "19.0.1"},
+		"@babel/core":       {Latest: "7.24.0"},
+		"@babel/preset-env": {Latest: "7.24.0"},
+		"@babel/parser":     {Latest: "7.24.1"},
+		"lodash":            {Latest: "4.17.21"},
+	}
+
+	groups := []Group{{Name: "react", Patterns: []string{"react", "react-dom", "@types/react"}}}
+
+	Assign(groups, true, versionComparison)
+
+	expected := map[string]string{
+		"react":             "react",
+		"react-dom":         "react",
+		"@types/react":      "react",
+		"@babel/core":       "@babel@7.24.0",
+		"@babel/preset-env": "@babel@7.24.0",
+		"@babel/parser":     "",
+		"lodash":            "",
+	}
+
+	for name, group := range expected {
+		if versionComparison[name].Group != group {
+			t.Errorf("expected group %q but got %q for %s", group, versionComparison[name].Group, name)
+		}
+	}
+}
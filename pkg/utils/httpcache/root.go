@@ -0,0 +1,289 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mode decides when the network is used
+type Mode string
+
+const (
+	// Use the cached response while fresh, revalidate it otherwise
+	ModeDefault Mode = ""
+	// Use the cached response even if stale, only fetch what is not cached
+	ModePreferOffline Mode = "prefer-offline"
+	// Never use the network
+	ModeOffline Mode = "offline"
+)
+
+var ErrNotCached = errors.New("not cached, can't fetch it offline")
+
+// StatusError is returned for responses that are not 200 OK or 304 Not Modified
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code: %d", e.StatusCode)
+}
+
+// Cache stores GET responses on disk, revalidating them with their ETag and Last-Modified headers
+type Cache struct {
+	// Responses are not stored if empty
+	Dir    string
+	Client *http.Client
+	now    func() time.Time
+}
+
+type entry struct {
+	Url          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	CacheControl string    `json:"cacheControl,omitempty"`
+	StoredAt     time.Time `json:"storedAt"`
+	// Zero when the response must be revalidated before using it
+	FreshUntil time.Time `json:"freshUntil"`
+}
+
+// DefaultDir returns the directory inside the user cache dir, like "~/.cache/up-npm/registry"
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "up-npm", "registry"), nil
+}
+
+func New(dir string) *Cache {
+	return &Cache{
+		Dir:    dir,
+		Client: http.DefaultClient,
+		now:    time.Now,
+	}
+}
+
+/*
+Get returns the body of a successful GET request, from the cache when the mode allows it.
+
+Responses are keyed by URL and Accept header. A stale response is used if the registry can't be reached.
+*/
+func (c *Cache) Get(req *http.Request, mode Mode) ([]byte, error) {
+
+	key := getKey(req)
+	cached, body, hasCached := c.load(key)
+
+	if hasCached {
+		if mode == ModeOffline || mode == ModePreferOffline || c.now().Before(cached.FreshUntil) {
+			return body, nil
+		}
+
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	if mode == ModeOffline {
+		return nil, fmt.Errorf("%s: %w", req.URL, ErrNotCached)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		if hasCached {
+			return body, nil
+		}
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "" {
+			cached.CacheControl = cacheControl
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			cached.ETag = etag
+		}
+		cached.StoredAt = c.now()
+		cached.FreshUntil = getFreshUntil(cached.CacheControl, resp.Header.Get("Age"), cached.StoredAt)
+
+		c.store(key, cached, nil)
+
+		return body, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheControl := resp.Header.Get("Cache-Control")
+
+	if _, noStore := parseCacheControl(cacheControl)["no-store"]; noStore {
+		c.remove(key)
+		return body, nil
+	}
+
+	now := c.now()
+	c.store(key, entry{
+		Url:          req.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		CacheControl: cacheControl,
+		StoredAt:     now,
+		FreshUntil:   getFreshUntil(cacheControl, resp.Header.Get("Age"), now),
+	}, body)
+
+	return body, nil
+}
+
+func getKey(req *http.Request) string {
+	hash := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept")))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *Cache) paths(key string) (metadataPath string, bodyPath string) {
+	return filepath.Join(c.Dir, key+".json"), filepath.Join(c.Dir, key+".body")
+}
+
+func (c *Cache) load(key string) (entry, []byte, bool) {
+
+	if c.Dir == "" {
+		return entry{}, nil, false
+	}
+
+	metadataPath, bodyPath := c.paths(key)
+
+	metadata, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return entry{}, nil, false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(metadata, &cached); err != nil {
+		return entry{}, nil, false
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return entry{}, nil, false
+	}
+
+	return cached, body, true
+}
+
+// store writes the metadata and, if not nil, the body. The cache is best effort so errors are ignored
+func (c *Cache) store(key string, cached entry, body []byte) {
+
+	if c.Dir == "" {
+		return
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return
+	}
+
+	metadataPath, bodyPath := c.paths(key)
+
+	if body != nil {
+		if err := writeFileAtomic(bodyPath, body); err != nil {
+			return
+		}
+	}
+
+	metadata, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	writeFileAtomic(metadataPath, metadata)
+}
+
+func (c *Cache) remove(key string) {
+	if c.Dir == "" {
+		return
+	}
+
+	metadataPath, bodyPath := c.paths(key)
+	os.Remove(metadataPath)
+	os.Remove(bodyPath)
+}
+
+// writeFileAtomic avoids leaving half written files when several processes share the cache
+func writeFileAtomic(path string, data []byte) error {
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// parseCacheControl returns the directives of a Cache-Control header, like {"public": "", "max-age": "300"}
+func parseCacheControl(header string) map[string]string {
+
+	directives := map[string]string{}
+
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(value, "\"")
+	}
+
+	return directives
+}
+
+// getFreshUntil returns until when a response can be used without revalidating it, zero if it must always be revalidated
+func getFreshUntil(cacheControl string, age string, storedAt time.Time) time.Time {
+
+	directives := parseCacheControl(cacheControl)
+
+	if _, noCache := directives["no-cache"]; noCache {
+		return time.Time{}
+	}
+
+	maxAge, err := strconv.Atoi(directives["max-age"])
+	if err != nil || maxAge <= 0 {
+		return time.Time{}
+	}
+
+	// Time already spent in other caches like CDNs
+	if ageSeconds, err := strconv.Atoi(age); err == nil && ageSeconds > 0 {
+		maxAge -= ageSeconds
+	}
+
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+
+	return storedAt.Add(time.Duration(maxAge) * time.Second)
+}
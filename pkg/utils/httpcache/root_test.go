@@ -0,0 +1,156 @@
+package httpcache
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		cacheControl string
+		// Time between both requests
+		elapsed          time.Duration
+		mode             Mode
+		expectedRequests int32
+	}{
+		{
+			name:             "fresh response is not fetched again",
+			cacheControl:     "public, max-age=300",
+			elapsed:          time.Minute,
+			expectedRequests: 1,
+		},
+		{
+			name:             "stale response is revalidated",
+			cacheControl:     "public, max-age=300",
+			elapsed:          time.Hour,
+			expectedRequests: 2,
+		},
+		{
+			name:             "no-cache is always revalidated",
+			cacheControl:     "no-cache",
+			expectedRequests: 2,
+		},
+		{
+			name:             "no-store is never cached",
+			cacheControl:     "no-store",
+			expectedRequests: 2,
+		},
+		{
+			name:             "prefer offline uses stale response",
+			cacheControl:     "max-age=300",
+			elapsed:          time.Hour,
+			mode:             ModePreferOffline,
+			expectedRequests: 1,
+		},
+		{
+			name:             "offline uses stale response",
+			cacheControl:     "max-age=300",
+			elapsed:          time.Hour,
+			mode:             ModeOffline,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int32
+
+			// Answers 304 when revalidated with the ETag
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Cache-Control", tc.cacheControl)
+
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				w.Write([]byte("body"))
+			}))
+			defer server.Close()
+
+			currentTime := now
+			cache := New(t.TempDir())
+			cache.now = func() time.Time { return currentTime }
+
+			for i := 0; i < 2; i++ {
+				mode := ModeDefault
+				if i == 1 {
+					mode = tc.mode
+					currentTime = now.Add(tc.elapsed)
+				}
+
+				req, _ := http.NewRequest("GET", server.URL+"/react", nil)
+
+				body, err := cache.Get(req, mode)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(body) != "body" {
+					t.Errorf("expected body %q but got %q on request %d", "body", body, i+1)
+				}
+			}
+
+			if actual := atomic.LoadInt32(&requests); actual != tc.expectedRequests {
+				t.Errorf("expected %d requests but got %d", tc.expectedRequests, actual)
+			}
+		})
+	}
+}
+
+func TestGetOfflineNotCached(t *testing.T) {
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/react", nil)
+
+	_, err := New(t.TempDir()).Get(req, ModeOffline)
+	if !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected error %v but got %v", ErrNotCached, err)
+	}
+
+	if actual := atomic.LoadInt32(&requests); actual != 0 {
+		t.Errorf("expected 0 requests but got %d", actual)
+	}
+}
+
+func TestGetFreshUntil(t *testing.T) {
+
+	storedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		cacheControl string
+		age          string
+		expected     time.Time
+	}{
+		{cacheControl: "public, max-age=300", age: "", expected: storedAt.Add(300 * time.Second)},
+		{cacheControl: "public, max-age=300", age: "100", expected: storedAt.Add(200 * time.Second)},
+		{cacheControl: "max-age=300", age: "400", expected: time.Time{}},
+		{cacheControl: "max-age=300, no-cache", age: "", expected: time.Time{}},
+		{cacheControl: "public", age: "", expected: time.Time{}},
+		{cacheControl: "", age: "", expected: time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		freshUntil := getFreshUntil(tc.cacheControl, tc.age, storedAt)
+		if !freshUntil.Equal(tc.expected) {
+			t.Errorf("expected %v but got %v for %q and %q", tc.expected, freshUntil, tc.cacheControl, tc.age)
+		}
+	}
+}
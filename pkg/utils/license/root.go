@@ -0,0 +1,123 @@
+package license
+
+import (
+	"strings"
+)
+
+// ParseAllowedLicenses parses a comma separated list like "MIT,Apache-2.0,ISC"
+func ParseAllowedLicenses(list string) []string {
+
+	var licenses []string
+
+	for _, license := range strings.Split(list, ",") {
+		license = strings.TrimSpace(license)
+		if license != "" {
+			licenses = append(licenses, license)
+		}
+	}
+
+	return licenses
+}
+
+// Format returns the license for printing
+func Format(license string) string {
+	if license == "" {
+		return "no license"
+	}
+	return license
+}
+
+/*
+IsAllowed checks if a SPDX expression like "MIT", "(MIT OR GPL-3.0)" or "Apache-2.0 AND BSD-3-Clause"
+can be used with the allowed licenses: every license joined with AND must be allowed, one of the
+licenses joined with OR is enough. Empty expressions are never allowed.
+*/
+func IsAllowed(expression string, allowed []string) bool {
+
+	tokens := tokenize(expression)
+	if len(tokens) == 0 {
+		return false
+	}
+
+	p := parser{tokens: tokens, allowed: allowed}
+
+	result := p.parseOr()
+
+	// Anything left means the expression is malformed
+	return result && p.pos == len(tokens)
+}
+
+func tokenize(expression string) []string {
+	expression = strings.ReplaceAll(expression, "(", " ( ")
+	expression = strings.ReplaceAll(expression, ")", " ) ")
+	return strings.Fields(expression)
+}
+
+type parser struct {
+	tokens  []string
+	pos     int
+	allowed []string
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// OR has lower precedence than AND
+func (p *parser) parseOr() bool {
+	result := p.parseAnd()
+
+	for strings.EqualFold(p.peek(), "OR") {
+		p.pos++
+		right := p.parseAnd()
+		result = result || right
+	}
+
+	return result
+}
+
+func (p *parser) parseAnd() bool {
+	result := p.parseLicense()
+
+	for strings.EqualFold(p.peek(), "AND") {
+		p.pos++
+		right := p.parseLicense()
+		result = result && right
+	}
+
+	return result
+}
+
+func (p *parser) parseLicense() bool {
+	token := p.peek()
+	p.pos++
+
+	switch token {
+	case "":
+		return false
+	case "(":
+		result := p.parseOr()
+		if p.peek() != ")" {
+			return false
+		}
+		p.pos++
+		return result
+	}
+
+	// Exceptions like "GPL-2.0 WITH Classpath-exception-2.0" are checked as a whole
+	if strings.EqualFold(p.peek(), "WITH") && p.pos+1 < len(p.tokens) {
+		token = token + " WITH " + p.tokens[p.pos+1]
+		p.pos += 2
+	}
+
+	for _, allowed := range p.allowed {
+		if strings.EqualFold(allowed, token) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package license
+
+import "testing"
+
+func TestIsAllowed(t *testing.T) {
+	allowed := ParseAllowedLicenses("MIT, Apache-2.0,ISC")
+
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: "MIT", expected: true},
+		{expression: "mit", expected: true},
+		{expression: "BUSL-1.1", expected: false},
+		{expression: "(MIT OR GPL-3.0)", expected: true},
+		{expression: "SSPL-1.0 OR BUSL-1.1", expected: false},
+		{expression: "MIT AND ISC", expected: true},
+		{expression: "MIT AND GPL-3.0", expected: false},
+		{expression: "GPL-3.0 OR (MIT AND ISC)", expected: true},
+		{expression: "GPL-3.0 OR MIT AND SSPL-1.0", expected: false},
+		{expression: "(MIT", expected: false},
+		{expression: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			isAllowed := IsAllowed(tc.expression, allowed)
+			if isAllowed != tc.expected {
+				t.Errorf("expected %v but got %v for %q", tc.expected, isAllowed, tc.expression)
+			}
+		})
+	}
+}
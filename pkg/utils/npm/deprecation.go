@@ -0,0 +1,49 @@
+package npm
+
+import (
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+// getDeprecationMessage returns the deprecation message of a version, "" if it is not deprecated
+func getDeprecationMessage(versions map[string]registry.Manifest, v string) string {
+	return string(versions[v].Deprecated)
+}
+
+/*
+getNewestNonDeprecatedVersion returns the newest stable version above current and up to latest that is not deprecated,
+"" if all of them are.
+
+An empty current or latest leaves that side open.
+*/
+func getNewestNonDeprecatedVersion(versions map[string]registry.Manifest, current string, latest string) string {
+
+	var newest version.Semver
+	newestVersion := ""
+
+	for v := range versions {
+		semver, err := version.ParseSemver(v)
+		if err != nil || semver.IsPrerelease() {
+			continue
+		}
+
+		if current != "" && version.Compare(v, current) <= 0 {
+			continue
+		}
+
+		if latest != "" && version.Compare(v, latest) > 0 {
+			continue
+		}
+
+		if getDeprecationMessage(versions, v) != "" {
+			continue
+		}
+
+		if newestVersion == "" || version.CompareSemver(semver, newest) > 0 {
+			newest = semver
+			newestVersion = v
+		}
+	}
+
+	return newestVersion
+}
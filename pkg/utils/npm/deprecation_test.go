@@ -0,0 +1,87 @@
+package npm
+
+import (
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+func TestGetNewestNonDeprecatedVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		versions map[string]registry.Manifest
+		current  string
+		latest   string
+		expected string
+	}{
+		{
+			name: "latest deprecated",
+			versions: map[string]registry.Manifest{
+				"1.0.0": {},
+				"1.1.0": {},
+				"2.0.0": {Deprecated: "broken release"},
+			},
+			expected: "1.1.0",
+		},
+		{
+			name: "prereleases are ignored",
+			versions: map[string]registry.Manifest{
+				"1.0.0":        {},
+				"2.0.0-beta.1": {},
+				"2.0.0":        {Deprecated: "broken release"},
+			},
+			expected: "1.0.0",
+		},
+		{
+			name: "fully deprecated",
+			versions: map[string]registry.Manifest{
+				"1.0.0": {Deprecated: "use other-package"},
+				"2.0.0": {Deprecated: "use other-package"},
+			},
+			expected: "",
+		},
+		{
+			name: "not above latest",
+			versions: map[string]registry.Manifest{
+				"1.0.0": {},
+				"1.1.0": {Deprecated: "broken release"},
+				"2.0.0": {},
+			},
+			current:  "1.0.0",
+			latest:   "1.1.0",
+			expected: "",
+		},
+		{
+			name: "above current",
+			versions: map[string]registry.Manifest{
+				"1.0.0": {},
+				"1.1.0": {},
+				"1.2.0": {Deprecated: "broken release"},
+			},
+			current:  "1.1.0",
+			latest:   "1.2.0",
+			expected: "",
+		},
+		{
+			name: "between current and latest",
+			versions: map[string]registry.Manifest{
+				"1.0.0": {},
+				"1.1.0": {},
+				"1.2.0": {Deprecated: "broken release"},
+				"2.0.0": {},
+			},
+			current:  "1.0.0",
+			latest:   "1.2.0",
+			expected: "1.1.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := getNewestNonDeprecatedVersion(tc.versions, tc.current, tc.latest)
+			if actual != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package npm
+
+import (
+	"math"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	repositorypkg "github.com/icaruk/up-npm/pkg/utils/repository"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+/*
+needsFullDocument checks if the outdated packages need the full document.
+
+Their licenses, release age and risks are summarized, prompted, applied and reported, only --check without
+--allowed-licenses lists them without those.
+*/
+func needsFullDocument(cfg CmdFlags) bool {
+	return !cfg.Check || len(cfg.AllowedLicenses) > 0
+}
+
+// setPackageDetails sets the fields of the item that need the full document
+func setPackageDetails(item *version.VersionComparisonItem, packument registry.Packument) {
+
+	item.Homepage = string(packument.Homepage)
+
+	if packument.Repository.Url != "" {
+		item.RepositoryUrl = repositorypkg.GetRepositoryUrl(packument.Repository.Url)
+	}
+
+	// Unknown when the registry has no date for the version
+	item.HoursSinceLasRelease = -1
+	if latestReleaseDate, ok := packument.Time[item.Latest]; ok {
+		// Round to 1 decimal
+		item.HoursSinceLasRelease = math.Round(time.Since(latestReleaseDate).Hours()*10) / 10
+	}
+
+	item.CurrentLicense = getLicense(packument.Versions, item.Current)
+	item.LatestLicense = getLicense(packument.Versions, item.Latest)
+	item.Risks = getRiskSignals(packument.Versions, item.Current, item.Latest)
+}
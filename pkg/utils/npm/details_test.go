@@ -0,0 +1,51 @@
+package npm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestSetPackageDetails(t *testing.T) {
+	packument := registry.Packument{
+		Versions: map[string]registry.Manifest{
+			"1.0.0": {License: "MIT"},
+			"2.0.0": {License: "ISC", Scripts: registry.StringMap{"postinstall": "node setup.js"}},
+		},
+		Time:       registry.Times{"2.0.0": time.Now().Add(-5 * time.Hour)},
+		Homepage:   "https://example.com",
+		Repository: registry.Repository{Url: "git+https://github.com/example/example.git"},
+	}
+
+	testCases := []struct {
+		name          string
+		latest        string
+		expectedHours bool
+	}{
+		{name: "release date", latest: "2.0.0", expectedHours: true},
+		{name: "missing release date", latest: "3.0.0", expectedHours: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			item := version.VersionComparisonItem{Current: "1.0.0", Latest: tc.latest}
+			setPackageDetails(&item, packument)
+
+			if item.Homepage != "https://example.com" || item.RepositoryUrl == "" {
+				t.Errorf("expected details to be set, got %+v", item)
+			}
+			if (item.HoursSinceLasRelease >= 0) != tc.expectedHours {
+				t.Errorf("expected known release age %v, got %v hours", tc.expectedHours, item.HoursSinceLasRelease)
+			}
+		})
+	}
+
+	item := version.VersionComparisonItem{Current: "1.0.0", Latest: "2.0.0"}
+	setPackageDetails(&item, packument)
+
+	if item.CurrentLicense != "MIT" || item.LatestLicense != "ISC" || len(item.Risks) != 1 {
+		t.Errorf("expected licenses MIT -> ISC and 1 risk, got %s -> %s and %v", item.CurrentLicense, item.LatestLicense, item.Risks)
+	}
+}
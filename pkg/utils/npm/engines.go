@@ -0,0 +1,63 @@
+package npm
+
+import (
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+// getEnginesNode returns the "engines.node" range of a version
+func getEnginesNode(versions map[string]registry.Manifest, v string) string {
+	return versions[v].Engines["node"]
+}
+
+// supportsNode checks if a version can run on nodeVersion, versions without "engines.node" run anywhere
+func supportsNode(versions map[string]registry.Manifest, v string, nodeVersion string) bool {
+	enginesNode := getEnginesNode(versions, v)
+	if enginesNode == "" {
+		return true
+	}
+
+	satisfies, err := version.Satisfies(nodeVersion, enginesNode)
+	if err != nil {
+		// Unparseable ranges are not our business
+		return true
+	}
+
+	return satisfies
+}
+
+// getNodeCompatibleVersion returns the newest stable version above currentVersion and up to maxVersion that runs on nodeVersion, "" if there is none
+func getNodeCompatibleVersion(versions map[string]registry.Manifest, currentVersion string, maxVersion string, nodeVersion string) string {
+
+	current, err := version.ParseSemver(currentVersion)
+	if err != nil {
+		return ""
+	}
+	max, err := version.ParseSemver(maxVersion)
+	if err != nil {
+		return ""
+	}
+
+	var newest version.Semver
+	newestVersion := ""
+
+	for v := range versions {
+		semver, err := version.ParseSemver(v)
+		if err != nil || semver.IsPrerelease() {
+			continue
+		}
+		if version.CompareSemver(semver, current) <= 0 || version.CompareSemver(semver, max) > 0 {
+			continue
+		}
+		if !supportsNode(versions, v, nodeVersion) {
+			continue
+		}
+
+		if newestVersion == "" || version.CompareSemver(semver, newest) > 0 {
+			newest = semver
+			newestVersion = v
+		}
+	}
+
+	return newestVersion
+}
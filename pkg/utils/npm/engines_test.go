@@ -0,0 +1,40 @@
+package npm
+
+import (
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+func TestGetNodeCompatibleVersion(t *testing.T) {
+	versions := map[string]registry.Manifest{
+		"1.0.0":       {},
+		"1.1.0":       {Engines: registry.StringMap{"node": ">=16"}},
+		"1.2.0":       {Engines: registry.StringMap{"node": ">=18"}},
+		"2.0.0-rc.0":  {Engines: registry.StringMap{"node": ">=18"}},
+		"2.0.0":       {Engines: registry.StringMap{"node": ">=20"}},
+		"2.1.0":       {Engines: registry.StringMap{"node": "^20.10.0 || >=22"}},
+		"bad-version": {},
+	}
+
+	testCases := []struct {
+		name        string
+		current     string
+		nodeVersion string
+		expected    string
+	}{
+		{name: "latest supports node", current: "1.0.0", nodeVersion: "22.0.0", expected: "2.1.0"},
+		{name: "newest supporting node 18", current: "1.0.0", nodeVersion: "18.0.0", expected: "1.2.0"},
+		{name: "newest supporting node 16", current: "1.0.0", nodeVersion: "16.20.0", expected: "1.1.0"},
+		{name: "nothing newer supports node 16", current: "1.1.0", nodeVersion: "16.20.0", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compatibleVersion := getNodeCompatibleVersion(versions, tc.current, "2.1.0", tc.nodeVersion)
+			if compatibleVersion != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, compatibleVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+
+	"github.com/icaruk/up-npm/pkg/utils/httpcache"
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+// FailureCategory is why a package could not be checked
+type FailureCategory string
+
+const (
+	FailureNotFound        FailureCategory = "not found"
+	FailureUnauthorized    FailureCategory = "unauthorized"
+	FailureRateLimited     FailureCategory = "rate limited"
+	FailureTimeout         FailureCategory = "timeout"
+	FailureNotCached       FailureCategory = "not cached"
+	FailureNetwork         FailureCategory = "network error"
+	FailureServerError     FailureCategory = "registry error"
+	FailureInvalidResponse FailureCategory = "invalid response"
+	FailureOther           FailureCategory = "error"
+)
+
+// Failure is a package that could not be checked
+type Failure struct {
+	Name     string
+	IsDev    bool
+	Category FailureCategory
+	Err      error
+}
+
+// GetFailureCategory groups the errors of the registry client by their cause
+func GetFailureCategory(err error) FailureCategory {
+
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var registryErr *registry.Error
+
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		return FailureNotFound
+	case errors.Is(err, registry.ErrUnauthorized):
+		return FailureUnauthorized
+	case errors.Is(err, registry.ErrRateLimited):
+		return FailureRateLimited
+	case errors.Is(err, httpcache.ErrNotCached):
+		return FailureNotCached
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	case errors.As(err, &netErr):
+		return FailureNetwork
+	case errors.As(err, &registryErr) && registryErr.StatusCode >= 500:
+		return FailureServerError
+	case errors.As(err, &syntaxErr):
+		return FailureInvalidResponse
+	}
+
+	return FailureOther
+}
+
+// sortFailures sorts the failures by package name, they are added in the order the fetches finish
+func sortFailures(failures []Failure) {
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Name < failures[j].Name
+	})
+}
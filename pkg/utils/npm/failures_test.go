@@ -0,0 +1,62 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/httpcache"
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+func TestGetFailureCategory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected FailureCategory
+	}{
+		{name: "not found", err: &registry.Error{Package: "a", StatusCode: 404, Err: registry.ErrNotFound}, expected: FailureNotFound},
+		{name: "unauthorized", err: &registry.Error{Package: "a", StatusCode: 401, Err: registry.ErrUnauthorized}, expected: FailureUnauthorized},
+		{name: "rate limited", err: &registry.Error{Package: "a", StatusCode: 429, Err: registry.ErrRateLimited}, expected: FailureRateLimited},
+		{name: "server error", err: &registry.Error{Package: "a", StatusCode: 502, Err: &httpcache.StatusError{StatusCode: 502}}, expected: FailureServerError},
+		{name: "not cached", err: &registry.Error{Package: "a", Err: fmt.Errorf("url: %w", httpcache.ErrNotCached)}, expected: FailureNotCached},
+		{name: "deadline", err: &registry.Error{Package: "a", Err: context.DeadlineExceeded}, expected: FailureTimeout},
+		{name: "connection refused", err: &registry.Error{Package: "a", Err: &url.Error{Op: "Get", URL: "url", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}}, expected: FailureNetwork},
+		{name: "invalid json", err: &registry.Error{Package: "a", Err: &json.SyntaxError{}}, expected: FailureInvalidResponse},
+		{name: "other", err: errors.New("boom"), expected: FailureOther},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			category := GetFailureCategory(tc.err)
+			if category != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, category)
+			}
+		})
+	}
+}
+
+func TestFetchSummaryFailures(t *testing.T) {
+	summary := NewFetchSummary()
+	summary.Add(FetchResult{Name: "zod", Err: registry.ErrNotFound})
+	summary.Add(FetchResult{Name: "react", Item: nil})
+
+	devSummary := NewFetchSummary()
+	devSummary.Add(FetchResult{Name: "eslint", IsDev: true, Err: registry.ErrRateLimited})
+
+	summary.Merge(devSummary)
+
+	if len(summary.Failures) != 2 {
+		t.Fatalf("expected 2 failures but got %d", len(summary.Failures))
+	}
+	if summary.Failures[0].Name != "eslint" || !summary.Failures[0].IsDev || summary.Failures[0].Category != FailureRateLimited {
+		t.Errorf("expected eslint rate limited first but got %+v", summary.Failures[0])
+	}
+	if summary.Failures[1].Name != "zod" || summary.Failures[1].Category != FailureNotFound {
+		t.Errorf("expected zod not found second but got %+v", summary.Failures[1])
+	}
+}
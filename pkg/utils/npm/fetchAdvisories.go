@@ -0,0 +1,110 @@
+package npm
+
+import (
+	"github.com/icaruk/up-npm/pkg/utils/advisory"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+/*
+FetchAdvisories queries the bulk advisory endpoint for the current versions and stores the ones affecting them.
+
+Each package is sent to its own registry with its token, so the names of private packages never reach the public one.
+The advisories of the registries that answered are stored even if another one fails, returning the first error.
+*/
+func FetchAdvisories(
+	targetMap map[string]version.VersionComparisonItem,
+	token string,
+	cfg CmdFlags,
+) error {
+
+	if len(targetMap) == 0 {
+		return nil
+	}
+
+	// registry => packages
+	registryPackages := map[string]map[string][]string{}
+	for name, item := range targetMap {
+		registryUrl := GetPackageRegistryUrl(cfg, name)
+		if registryPackages[registryUrl] == nil {
+			registryPackages[registryUrl] = map[string][]string{}
+		}
+		registryPackages[registryUrl][name] = []string{item.Current}
+	}
+
+	advisories := map[string][]advisory.Advisory{}
+	var firstErr error
+
+	for registryUrl, packages := range registryPackages {
+		// Every package of a registry shares its token
+		var packageToken string
+		for name := range packages {
+			packageToken = getPackageToken(cfg, name, token)
+			break
+		}
+
+		registryAdvisories, err := advisory.FetchBulkAdvisories(cfg.HttpClient(), registryUrl, packageToken, packages)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for name, items := range registryAdvisories {
+			advisories[name] = items
+		}
+	}
+
+	SetAdvisories(targetMap, advisories)
+
+	return firstErr
+}
+
+// LoadAdvisories matches the current versions against an offline OSV database (directory or zip)
+func LoadAdvisories(
+	targetMap map[string]version.VersionComparisonItem,
+	cfg CmdFlags,
+) error {
+
+	advisories, err := advisory.LoadOSV(cfg.Advisories)
+	if err != nil {
+		return err
+	}
+
+	SetAdvisories(targetMap, advisories)
+
+	return nil
+}
+
+// SetAdvisories stores on each package the advisories whose vulnerable range includes its current version
+func SetAdvisories(
+	targetMap map[string]version.VersionComparisonItem,
+	advisories map[string][]advisory.Advisory,
+) {
+
+	for name, item := range targetMap {
+
+		var affecting []advisory.Advisory
+
+		for _, a := range advisories[name] {
+			affected, err := version.Satisfies(item.Current, a.VulnerableVersions)
+
+			// Keep advisories with ranges we can't parse, better a false positive
+			if err != nil || affected {
+				affecting = append(affecting, a)
+			}
+		}
+
+		item.Advisories = affecting
+		targetMap[name] = item
+	}
+}
+
+// FilterSecurityUpdates removes the packages whose latest version does not fix any advisory
+func FilterSecurityUpdates(targetMap map[string]version.VersionComparisonItem) {
+	for name, item := range targetMap {
+		if !item.LatestFixesAdvisories() {
+			delete(targetMap, name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package npm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestFetchAdvisoriesRegistries(t *testing.T) {
+
+	// Packages received by each registry, with the token they came with
+	received := map[string][]string{}
+	tokens := map[string]string{}
+
+	newRegistry := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var packages map[string][]string
+			json.NewDecoder(r.Body).Decode(&packages)
+
+			for packageName := range packages {
+				received[name] = append(received[name], packageName)
+			}
+			tokens[name] = r.Header.Get("Authorization")
+
+			w.Write([]byte(`{}`))
+		}))
+	}
+
+	public := newRegistry("public")
+	defer public.Close()
+	private := newRegistry("private")
+	defer private.Close()
+
+	cfg := CmdFlags{
+		Registry:   public.URL,
+		Registries: map[string]string{"@corp": private.URL},
+	}
+
+	targetMap := map[string]version.VersionComparisonItem{
+		"react":    {Current: "18.2.0"},
+		"@corp/ui": {Current: "1.0.0"},
+	}
+
+	if err := FetchAdvisories(targetMap, "token", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received["public"]) != 1 || received["public"][0] != "react" || tokens["public"] != "Bearer token" {
+		t.Errorf("expected react with the token on the public registry but got %v, %q", received["public"], tokens["public"])
+	}
+	if len(received["private"]) != 1 || received["private"][0] != "@corp/ui" || tokens["private"] != "" {
+		t.Errorf("expected @corp/ui without the token on the private registry but got %v, %q", received["private"], tokens["private"])
+	}
+}
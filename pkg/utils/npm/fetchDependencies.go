@@ -2,27 +2,242 @@ package npm
 
 import (
 	"fmt"
-	"math"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
-	repositorypkg "github.com/icaruk/up-npm/pkg/utils/repository"
+	"github.com/icaruk/up-npm/pkg/utils/config"
+	"github.com/icaruk/up-npm/pkg/utils/filter"
+	"github.com/icaruk/up-npm/pkg/utils/httpcache"
+	"github.com/icaruk/up-npm/pkg/utils/license"
+	"github.com/icaruk/up-npm/pkg/utils/registry"
 	"github.com/icaruk/up-npm/pkg/utils/version"
 
 	"github.com/schollz/progressbar/v3"
 )
 
 type CmdFlags struct {
-	NoDev          bool
-	AllowDowngrade bool
-	Filter         string
-	File           string
-	UpdatePatches  bool
+	NoDev            bool
+	AllowDowngrade   bool
+	Filter           string
+	File             string
+	UpdatePatches    bool
+	Install          string
+	Verify           string
+	Commit           string
+	CommitMessage    string
+	Branch           string
+	Report           string
+	Registry         string
+	NoAudit          bool
+	SecurityOnly     bool
+	Advisories       string
+	MinReleaseAge    time.Duration
+	VerifySignatures bool
+	AllowedLicenses  []string
+	NodeVersion      string
+	// engine-strict from .npmrc, versions not supporting NodeVersion are never offered
+	EngineStrict bool
+	// Update group definitions like "nestjs=@nestjs/*"
+	Groups    []string
+	AutoGroup bool
+	// From the project config
+	Ignore     []string
+	Ceilings   map[string]string
+	Registries map[string]string
+	Rules      []config.Rule
+	// Repeatable --include and --exclude patterns
+	Include []string
+	Exclude []string
+	// Update types to show, like ["minor", "patch"]
+	Only []string
+	// "dependencies" or "devDependencies", both if empty
+	Section string
+	// Use only the cached registry documents / use them even if stale
+	Offline       bool
+	PreferOffline bool
+	// Packages fetched at the same time
+	Concurrency int
+	// Ask for each package as soon as it is fetched
+	Stream bool
+	// Only print the outdated packages, exiting with a non-zero code
+	Check bool
+	// File where --check writes its results
+	Json string
+	// Only check the packages locked to an exact version / don't offer updates for them
+	IncludeLocked bool
+	ExcludeLocked bool
+	// How the updated versions are written, one of PinStyles
+	PinStyle string
+	// Proxy and TLS settings of .npmrc, the default transport if nil
+	Transport *http.Transport
+	// Directory of the cached registry documents, nothing is cached if empty
+	CacheDir string
 }
 
-const concurrencyLimit int = 10
+// HasFilters checks if some flag hides part of the dependencies
+func (cfg CmdFlags) HasFilters() bool {
+	return cfg.Filter != "" || cfg.SecurityOnly ||
+		len(cfg.Include) > 0 || len(cfg.Exclude) > 0 || len(cfg.Only) > 0 || cfg.Section != "" ||
+		cfg.IncludeLocked || cfg.ExcludeLocked
+}
+
+// CacheMode returns how the cached registry documents are used, --offline wins over --prefer-offline
+func (cfg CmdFlags) CacheMode() httpcache.Mode {
+	switch {
+	case cfg.Offline:
+		return httpcache.ModeOffline
+	case cfg.PreferOffline:
+		return httpcache.ModePreferOffline
+	}
+	return httpcache.ModeDefault
+}
+
+// HttpClient returns a client using the network settings of .npmrc, for the requests outside the registry client
+func (cfg CmdFlags) HttpClient() *http.Client {
+	if cfg.Transport == nil {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: cfg.Transport}
+}
+
+// Packages fetched at the same time when --concurrency is not set
+const DefaultConcurrency int = 10
+
+// package.json sections allowed on --section
+const (
+	SectionDependencies    = "dependencies"
+	SectionDevDependencies = "devDependencies"
+)
+
+// FetchSummary has the data about the fetched dependencies that is not part of the updatable packages
+type FetchSummary struct {
+	// Checked packages locked to an exact version, outdated or not
+	LockedDependencyCount int
+	// Locked packages with an update or not checked because of --exclude-locked, name => message
+	Locked map[string]string
+	// Packages where every version is deprecated, name => deprecation message
+	FullyDeprecated map[string]string
+	// Updates not allowed by the config, name => reason
+	Blocked map[string]string
+	// Every fetched package, outdated or not
+	Current map[string]CurrentPackage
+	// Packages held back or ignored by the rules of the project config, name => reason
+	Held map[string]string
+	// Packages with a version of package.json that can't be checked, name => version
+	Unsupported map[string]string
+	// Packages that could not be checked, sorted by name
+	Failures []Failure
+}
+
+// FetchResult is the outcome of checking one package
+type FetchResult struct {
+	Name  string
+	IsDev bool
+	// Locked to an exact version on package.json
+	IsLocked bool
+	// Update to offer, nil if there is none
+	Item *version.VersionComparisonItem
+	// Nil if the package was not fetched
+	Current *CurrentPackage
+	// Messages of the summary sections, "" when they don't apply
+	FullyDeprecated string
+	Blocked         string
+	Held            string
+	Locked          string
+	// Version of package.json that can't be checked, "" if it is valid
+	Unsupported string
+	Err         error
+}
+
+func NewFetchSummary() FetchSummary {
+	return FetchSummary{
+		FullyDeprecated: map[string]string{},
+		Blocked:         map[string]string{},
+		Current:         map[string]CurrentPackage{},
+		Held:            map[string]string{},
+		Locked:          map[string]string{},
+		Unsupported:     map[string]string{},
+	}
+}
+
+// Add records the parts of a result that are not the update itself
+func (summary *FetchSummary) Add(result FetchResult) {
+
+	if result.Current != nil {
+		summary.Current[result.Name] = *result.Current
+	}
+	if result.FullyDeprecated != "" {
+		summary.FullyDeprecated[result.Name] = result.FullyDeprecated
+	}
+	if result.Blocked != "" {
+		summary.Blocked[result.Name] = result.Blocked
+	}
+	if result.Held != "" {
+		summary.Held[result.Name] = result.Held
+	}
+	if result.IsLocked {
+		summary.LockedDependencyCount++
+	}
+	if result.Locked != "" {
+		summary.Locked[result.Name] = result.Locked
+	}
+	if result.Unsupported != "" {
+		summary.Unsupported[result.Name] = result.Unsupported
+	}
+	if result.Err != nil {
+		summary.Failures = append(summary.Failures, Failure{
+			Name:     result.Name,
+			IsDev:    result.IsDev,
+			Category: GetFailureCategory(result.Err),
+			Err:      result.Err,
+		})
+		sortFailures(summary.Failures)
+	}
+}
+
+// Merge adds the data of other to the summary
+func (summary *FetchSummary) Merge(other FetchSummary) {
+
+	summary.LockedDependencyCount += other.LockedDependencyCount
+
+	for name, message := range other.FullyDeprecated {
+		summary.FullyDeprecated[name] = message
+	}
+	for name, reason := range other.Blocked {
+		summary.Blocked[name] = reason
+	}
+	for name, currentPackage := range other.Current {
+		summary.Current[name] = currentPackage
+	}
+	for name, reason := range other.Held {
+		summary.Held[name] = reason
+	}
+	for name, message := range other.Locked {
+		summary.Locked[name] = message
+	}
+	for name, currentVersion := range other.Unsupported {
+		summary.Unsupported[name] = currentVersion
+	}
 
+	summary.Failures = append(summary.Failures, other.Failures...)
+	sortFailures(summary.Failures)
+}
+
+// dependencyJob is a package of package.json waiting to be fetched
+type dependencyJob struct {
+	name                string
+	isDev               bool
+	versionPrefix       string
+	cleanCurrentVersion string
+	isLocked            bool
+	rule                config.Rule
+	hasRule             bool
+}
+
+// FetchDependencies fetches every package of dependencyList, storing the updates on targetMap
 func FetchDependencies(
 	dependencyList map[string]string,
 	targetMap map[string]version.VersionComparisonItem,
@@ -30,136 +245,350 @@ func FetchDependencies(
 	token string,
 	bar *progressbar.ProgressBar,
 	cfg CmdFlags,
-) (lockedDependencyCount int) {
+) (summary FetchSummary) {
 
-	var wg sync.WaitGroup
-	var mutex sync.Mutex // to protect targetMap access
-	semaphoreChan := make(chan struct{}, concurrencyLimit)
-	resultsChan := make(chan string, len(dependencyList))
-	doneChan := make(chan struct{})
+	summary = NewFetchSummary()
 
-	defer func() {
-		close(semaphoreChan)
-	}()
+	var dependencies, devDependencies map[string]string
+	if isDev {
+		devDependencies = dependencyList
+	} else {
+		dependencies = dependencyList
+	}
 
-	for packageName, currentVersion := range dependencyList {
+	for result := range StreamDependencies(dependencies, devDependencies, token, cfg) {
+		summary.Add(result)
 
-		// Check filter
-		if cfg.Filter != "" {
-			if !strings.Contains(packageName, cfg.Filter) {
-				continue
-			}
+		if result.Item != nil {
+			targetMap[result.Name] = *result.Item
 		}
 
-		// Get version and prefix
-		versionPrefix, cleanCurrentVersion := version.GetCleanVersion(currentVersion)
-
-		if cleanCurrentVersion == "" {
-			fmt.Println(packageName, " has unsupported/invalid version, skipping...")
-			continue
+		if bar != nil {
+			bar.Add(1)
 		}
+	}
 
-		wg.Add(1)
+	return summary
 
-		go func(dependency string, currentVersion string) {
-			defer func() {
-				wg.Done()
-				<-semaphoreChan
-			}()
-			semaphoreChan <- struct{}{}
-
-			// Perform get request to npm registry
-			body, err := FetchNpmRegistry(dependency, token)
-			if err != nil {
-				fmt.Println("Failed to fetch", dependency, "from npm registry, skipping...")
-				resultsChan <- "" // Enviar un resultado vacío para que se tenga en cuenta en la cuenta de resultados
-				return
-			}
+}
 
-			distTags := body["dist-tags"].(map[string]any)
+/*
+StreamDependencies fetches the packages with cfg.Concurrency workers, sending each result as soon as it is ready.
 
-			var homepage string
-			if body["homepage"] != nil {
-				homepage = body["homepage"].(string)
-			}
+The channel is closed once every package has been checked. Packages filtered out by the flags or the project
+config send nothing, packages ignored by a rule send their Held message without being fetched.
+*/
+func StreamDependencies(
+	dependencies map[string]string,
+	devDependencies map[string]string,
+	token string,
+	cfg CmdFlags,
+) <-chan FetchResult {
+
+	now := time.Now()
 
-			var repositoryData map[string]any
-			if body["repository"] != nil {
-				repositoryData = body["repository"].(map[string]any)
+	// Patterns are validated when parsing the flags
+	packageFilter, _ := filter.New(cfg.Include, cfg.Exclude)
+
+	resultsChan := make(chan FetchResult, len(dependencies)+len(devDependencies))
+
+	var jobs []dependencyJob
+
+	for _, list := range []struct {
+		dependencies map[string]string
+		isDev        bool
+	}{{dependencies, false}, {devDependencies, true}} {
+
+		for packageName, currentVersion := range list.dependencies {
+
+			// Check filter
+			if cfg.Filter != "" {
+				if !strings.Contains(packageName, cfg.Filter) {
+					continue
+				}
 			}
 
-			var repositoryUrl string
-			if repositoryData["url"] != nil {
-				repositoryUrl = repositorypkg.GetRepositoryUrl(repositoryData["url"].(string))
+			if isIgnored(cfg.Ignore, packageName) || !packageFilter.Allows(packageName) {
+				continue
 			}
 
-			// Get latest version from distTags
-			var latestVersion string
-			for key := range distTags {
-				if key == "latest" {
-					latestVersion = distTags[key].(string)
-				}
+			// Get version and prefix
+			versionPrefix, cleanCurrentVersion := version.GetCleanVersion(currentVersion)
+
+			if cleanCurrentVersion == "" {
+				resultsChan <- FetchResult{Name: packageName, IsDev: list.isDev, Unsupported: fmt.Sprintf("%q", currentVersion)}
+				continue
 			}
 
-			// Get latest version inside time
-			versionTimes := body["time"].(map[string]any)
-			var latestReleaseDate string
+			isLocked := version.IsLocked(currentVersion)
+			if cfg.IncludeLocked && !isLocked {
+				continue
+			}
 
-			for key := range versionTimes {
-				if key == latestVersion {
-					latestReleaseDate = versionTimes[key].(string)
+			// Still counted and listed, they are only not fetched
+			if cfg.ExcludeLocked && isLocked {
+				resultsChan <- FetchResult{
+					Name:     packageName,
+					IsDev:    list.isDev,
+					IsLocked: true,
+					Locked:   fmt.Sprintf("%s, not checked", cleanCurrentVersion),
 				}
+				continue
 			}
 
-			hoursSinceLasRelease := -1.0
+			rule, hasRule := findRule(cfg.Rules, packageName, now)
+			if hasRule && rule.MaxVersion == "" {
+				resultsChan <- FetchResult{Name: packageName, IsDev: list.isDev, IsLocked: isLocked, Held: getRuleMessage(rule, "")}
+				continue
+			}
+
+			jobs = append(jobs, dependencyJob{
+				name:                packageName,
+				isDev:               list.isDev,
+				versionPrefix:       versionPrefix,
+				cleanCurrentVersion: cleanCurrentVersion,
+				isLocked:            isLocked,
+				rule:                rule,
+				hasRule:             hasRule,
+			})
+		}
+	}
+
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	jobsChan := make(chan dependencyJob)
+
+	// Producer
+	go func() {
+		for _, job := range jobs {
+			jobsChan <- job
+		}
+		close(jobsChan)
+	}()
+
+	// Shared by the consumers, reusing the connections to the registry
+	client := newRegistryClient(cfg)
 
-			// Parse ISOstring to date
-			latestReleaseDateParsed, err := time.Parse(time.RFC3339, latestReleaseDate)
-			if err != nil {
-				fmt.Println("Failed to parse latest release date for", dependency, "skipping...")
+	// Consumers, resultsChan is big enough so they never wait for the reader
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobsChan {
+				resultsChan <- fetchDependency(job, client, token, cfg)
 			}
+		}()
+	}
 
-			// Get difference in hours
-			hoursSinceLasRelease = time.Since(latestReleaseDateParsed).Hours()
-
-			// Round to 2 decimals
-			hoursSinceLasRelease = math.Round(hoursSinceLasRelease*10) / 10
-
-			// Get version update type (major, minor, patch, none)
-			upgradeType, upgradeDirection := version.GetVersionUpdateType(cleanCurrentVersion, latestVersion)
-
-			// Save data
-			if (upgradeDirection == version.Upgrade) ||
-				(cfg.AllowDowngrade && upgradeDirection == version.Downgrade) {
-				mutex.Lock()
-				targetMap[dependency] = version.VersionComparisonItem{
-					Current:              cleanCurrentVersion,
-					Latest:               latestVersion,
-					VersionType:          upgradeType,
-					ShouldUpdate:         false,
-					Homepage:             homepage,
-					RepositoryUrl:        repositoryUrl,
-					VersionPrefix:        versionPrefix,
-					IsDev:                isDev,
-					HoursSinceLasRelease: hoursSinceLasRelease,
-				}
-				mutex.Unlock()
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	return resultsChan
+
+}
+
+// fetchDependency checks the registry for an update of one package
+func fetchDependency(job dependencyJob, client *registry.Client, token string, cfg CmdFlags) FetchResult {
+
+	dependency := job.name
+	cleanCurrentVersion := job.cleanCurrentVersion
+	rule := job.rule
+
+	result := FetchResult{Name: dependency, IsDev: job.isDev, IsLocked: job.isLocked}
+
+	registryUrl := GetPackageRegistryUrl(cfg, dependency)
+	packageToken := getPackageToken(cfg, dependency, token)
+
+	// The abbreviated document is enough to find the update, release ages need the "time" of the full document
+	fetch := client.GetAbbreviatedPackument
+	isFullDocument := cfg.MinReleaseAge > 0
+	if isFullDocument {
+		fetch = client.GetPackument
+	}
+
+	// Perform get request to npm registry
+	packument, err := fetch(registryUrl, dependency, packageToken)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	latestVersion := packument.Latest()
+	versionTimes := packument.Time
+	versions := packument.Versions
+
+	// Never go above the ceiling of the project config
+	if ceiling := getCeiling(cfg.Ceilings, dependency); ceiling != "" {
+		latestVersion = getHighestVersionBelowCeiling(versions, latestVersion, ceiling)
+
+		if latestVersion == "" {
+			return result
+		}
+	}
+
+	result.Current = &CurrentPackage{
+		Version:          cleanCurrentVersion,
+		PeerDependencies: getPeerDependencies(versions, cleanCurrentVersion),
+	}
+
+	// Hold back to the max version of the rule
+	var heldReason string
+	if job.hasRule {
+		allowedVersion := getHighestVersionBelowCeiling(versions, latestVersion, rule.MaxVersion)
+
+		if allowedVersion != latestVersion {
+			result.Held = getRuleMessage(rule, latestVersion)
+
+			heldReason = rule.Reason
+			if heldReason == "" {
+				heldReason = rule.MaxVersion
 			}
+			latestVersion = allowedVersion
+		}
+
+		if latestVersion == "" {
+			return result
+		}
+	}
 
-			resultsChan <- ""
+	// Skip versions released too recently, using the newest one old enough instead
+	var tooRecentVersion string
+	if cfg.MinReleaseAge > 0 {
+		releasedBefore := time.Now().Add(-cfg.MinReleaseAge)
+		allowedVersion := getNewestVersionReleasedBefore(versionTimes, cleanCurrentVersion, latestVersion, releasedBefore)
 
-			if bar != nil {
-				bar.Add(1)
+		if allowedVersion != latestVersion {
+			tooRecentVersion = latestVersion
+			latestVersion = allowedVersion
+		}
+
+		if latestVersion == "" {
+			return result
+		}
+	}
+
+	// Check the latest version runs on our Node version
+	var requiredNode string
+	var nodeCompatibleVersion string
+	if cfg.NodeVersion != "" && !supportsNode(versions, latestVersion, cfg.NodeVersion) {
+		requiredNode = getEnginesNode(versions, latestVersion)
+		nodeCompatibleVersion = getNodeCompatibleVersion(versions, cleanCurrentVersion, latestVersion, cfg.NodeVersion)
+
+		if cfg.EngineStrict {
+			if nodeCompatibleVersion == "" {
+				result.Blocked = fmt.Sprintf("%s requires node %s (engine-strict)", latestVersion, requiredNode)
+				return result
 			}
 
-		}(packageName, currentVersion)
+			latestVersion = nodeCompatibleVersion
+			requiredNode = ""
+			nodeCompatibleVersion = ""
+		}
+	}
+
+	// Check deprecations
+
+	currentDeprecated := getDeprecationMessage(versions, cleanCurrentVersion)
+	latestDeprecated := getDeprecationMessage(versions, latestVersion)
+
+	// The suggestion stays within the ceiling, rule, release age and engine limits applied to latestVersion
+	var suggestedVersion string
+	if latestDeprecated != "" {
+		suggestedVersion = getNewestNonDeprecatedVersion(versions, cleanCurrentVersion, latestVersion)
+
+		if getNewestNonDeprecatedVersion(versions, "", "") == "" {
+			result.FullyDeprecated = latestDeprecated
+		}
+	}
+
+	// Get version update type (major, minor, patch, none)
+	upgradeType, upgradeDirection := version.GetVersionUpdateType(cleanCurrentVersion, latestVersion)
+
+	isUpdate := (upgradeDirection == version.Upgrade) ||
+		(cfg.AllowDowngrade && upgradeDirection == version.Downgrade)
+
+	if !isUpdate || (len(cfg.Only) > 0 && !slices.Contains(cfg.Only, string(upgradeType))) {
+		return result
+	}
+
+	// Up to date packages only need the abbreviated document
+	if needsFullDocument(cfg) && !isFullDocument {
+		packument, err = client.GetPackument(registryUrl, dependency, packageToken)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		isFullDocument = true
+		versionTimes = packument.Time
+		versions = packument.Versions
+	}
+
+	currentLicense := getLicense(versions, cleanCurrentVersion)
+	latestLicense := getLicense(versions, latestVersion)
+
+	// Block updates introducing a license that is not allowed
+	if len(cfg.AllowedLicenses) > 0 && latestLicense != currentLicense &&
+		!license.IsAllowed(latestLicense, cfg.AllowedLicenses) {
+
+		licenseSt := latestLicense
+		if licenseSt == "" {
+			licenseSt = "no license"
+		}
+
+		result.Blocked = fmt.Sprintf("%s changes license to %s, which is not allowed", latestVersion, licenseSt)
+		return result
+	}
 
+	var signatureError string
+	if cfg.VerifySignatures {
+		keys, err := getRegistryKeys(client, registryUrl, packageToken)
+		if err == nil {
+			err = verifyVersionSignature(keys, dependency, versions, latestVersion, versionTimes)
+		}
+		if err != nil {
+			signatureError = err.Error()
+		}
 	}
 
-	// Wait for all goroutines to complete
-	wg.Wait()
-	close(doneChan)
+	result.Item = &version.VersionComparisonItem{
+		Current:               cleanCurrentVersion,
+		Latest:                latestVersion,
+		VersionType:           upgradeType,
+		ShouldUpdate:          false,
+		VersionPrefix:         getPinPrefix(job.versionPrefix, cfg.PinStyle),
+		IsLocked:              job.isLocked,
+		IsDev:                 job.isDev,
+		HoursSinceLasRelease:  -1,
+		CurrentDeprecated:     currentDeprecated,
+		LatestDeprecated:      latestDeprecated,
+		SuggestedVersion:      suggestedVersion,
+		TooRecentVersion:      tooRecentVersion,
+		SignatureError:        signatureError,
+		RequiredNode:          requiredNode,
+		NodeCompatibleVersion: nodeCompatibleVersion,
+		PeerDependencies:      getPeerDependencies(versions, latestVersion),
+		HeldReason:            heldReason,
+	}
+
+	if nodeCompatibleVersion != "" {
+		result.Item.NodeCompatiblePeerDependencies = getPeerDependencies(versions, nodeCompatibleVersion)
+	}
+
+	if isFullDocument {
+		setPackageDetails(result.Item, packument)
+	}
+
+	if job.isLocked {
+		result.Locked = fmt.Sprintf("%s -> %s", cleanCurrentVersion, latestVersion)
+	}
 
-	return lockedDependencyCount
+	return result
 
 }
@@ -1,9 +1,16 @@
 package npm_test
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/icaruk/up-npm/pkg/utils/config"
 	"github.com/icaruk/up-npm/pkg/utils/npm"
 	"github.com/icaruk/up-npm/pkg/utils/version"
 	"github.com/schollz/progressbar/v3"
@@ -62,3 +69,164 @@ func TestFetchDependencies(t *testing.T) {
 		}
 	}
 }
+
+func TestStreamDependencies(t *testing.T) {
+	dependencies := map[string]string{
+		"react":  "^18.2.0",
+		"lodash": "^4.17.21",
+	}
+	devDependencies := map[string]string{
+		"eslint": "^8.0.0",
+	}
+
+	// Nothing is fetched, every package is either held or excluded
+	cfg := npm.CmdFlags{
+		Concurrency: 2,
+		Exclude:     []string{"lodash"},
+		Rules: []config.Rule{
+			{Name: "react", Reason: "waiting for the router"},
+			{Name: "eslint"},
+		},
+	}
+
+	results := map[string]npm.FetchResult{}
+	for result := range npm.StreamDependencies(dependencies, devDependencies, "", cfg) {
+		results[result.Name] = result
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results but got %d: %+v", len(results), results)
+	}
+
+	if results["react"].Held == "" || results["react"].IsDev {
+		t.Errorf("expected react to be held as a dependency but got %+v", results["react"])
+	}
+	if results["eslint"].Held == "" || !results["eslint"].IsDev {
+		t.Errorf("expected eslint to be held as a dev dependency but got %+v", results["eslint"])
+	}
+}
+
+func TestStreamDependenciesLocked(t *testing.T) {
+	dependencies := map[string]string{
+		"left-pad": "1.3.0",
+		"react":    "^18.2.0",
+		"vue":      "3.4",
+	}
+
+	// Nothing is fetched, locked packages are excluded and the rest are held
+	cfg := npm.CmdFlags{
+		ExcludeLocked: true,
+		Rules:         []config.Rule{{Name: "react"}, {Name: "vue"}},
+	}
+
+	summary := npm.NewFetchSummary()
+	for result := range npm.StreamDependencies(dependencies, nil, "", cfg) {
+		summary.Add(result)
+	}
+
+	if summary.LockedDependencyCount != 1 {
+		t.Errorf("expected 1 locked dependency but got %d", summary.LockedDependencyCount)
+	}
+	if summary.Locked["left-pad"] != "1.3.0, not checked" {
+		t.Errorf("expected left-pad not checked but got %v", summary.Locked)
+	}
+	if len(summary.Held) != 2 {
+		t.Errorf("expected react and vue to be held but got %v", summary.Held)
+	}
+
+	// Only locked packages, the others are skipped before the rules
+	cfg = npm.CmdFlags{
+		IncludeLocked: true,
+		ExcludeLocked: false,
+		Rules:         []config.Rule{{Name: "left-pad"}, {Name: "react"}},
+	}
+
+	var names []string
+	for result := range npm.StreamDependencies(dependencies, nil, "", cfg) {
+		names = append(names, result.Name)
+	}
+
+	if len(names) != 1 || names[0] != "left-pad" {
+		t.Errorf("expected left-pad only but got %v", names)
+	}
+}
+
+func TestStreamDependenciesConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	var inFlight, maxInFlight atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			highest := maxInFlight.Load()
+			if current <= highest || maxInFlight.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name == "slow" {
+			select {
+			case <-release:
+			case <-time.After(10 * time.Second):
+			}
+		}
+
+		fmt.Fprintf(w, `{"name": %q, "dist-tags": {"latest": "2.0.0"}, "versions": {"1.0.0": {}, "2.0.0": {}}}`, name)
+	}))
+	defer server.Close()
+
+	dependencies := map[string]string{
+		"slow":    "^1.0.0",
+		"fast-a":  "^1.0.0",
+		"fast-b":  "^1.0.0",
+		"fast-c":  "^1.0.0",
+		"invalid": "",
+	}
+
+	cfg := npm.CmdFlags{
+		Registry:    server.URL,
+		Concurrency: 2,
+		CacheDir:    t.TempDir(),
+	}
+
+	resultsChan := npm.StreamDependencies(dependencies, nil, "", cfg)
+
+	// Everything but the slow package arrives while it is still being fetched
+	results := map[string]npm.FetchResult{}
+	for len(results) < len(dependencies)-1 {
+		select {
+		case result := <-resultsChan:
+			results[result.Name] = result
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the results before the slow fetch finishes but got %d", len(results))
+		}
+	}
+
+	if _, ok := results["slow"]; ok {
+		t.Errorf("expected slow to be still loading but got its result")
+	}
+
+	close(release)
+
+	for result := range resultsChan {
+		results[result.Name] = result
+	}
+
+	for _, name := range []string{"slow", "fast-a", "fast-b", "fast-c"} {
+		result := results[name]
+		if result.Err != nil || result.Item == nil || result.Item.Latest != "2.0.0" {
+			t.Errorf("expected %s to have the update 2.0.0 but got %+v", name, result)
+		}
+	}
+
+	if results["invalid"].Unsupported == "" {
+		t.Errorf("expected invalid to be unsupported but got %+v", results["invalid"])
+	}
+
+	if actual := maxInFlight.Load(); actual > int32(cfg.Concurrency) {
+		t.Errorf("expected at most %d fetches at the same time but got %d", cfg.Concurrency, actual)
+	}
+}
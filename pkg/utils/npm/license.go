@@ -0,0 +1,34 @@
+package npm
+
+import (
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+// getLicense returns the license of a version, supporting the legacy "licenses" array
+func getLicense(versions map[string]registry.Manifest, v string) string {
+
+	manifest, ok := versions[v]
+	if !ok {
+		return ""
+	}
+
+	if manifest.License != "" {
+		return string(manifest.License)
+	}
+
+	// [{"type": "MIT", "url": "..."}, ...] means any of them
+	var types []string
+	for _, license := range manifest.Licenses {
+		if license != "" {
+			types = append(types, string(license))
+		}
+	}
+
+	if len(types) > 1 {
+		return "(" + strings.Join(types, " OR ") + ")"
+	}
+
+	return strings.Join(types, "")
+}
@@ -0,0 +1,20 @@
+package npm
+
+import "github.com/icaruk/up-npm/pkg/utils/registry"
+
+// CurrentPackage is the version on package.json of a fetched package
+type CurrentPackage struct {
+	Version          string
+	PeerDependencies map[string]string
+}
+
+// getPeerDependencies returns the "peerDependencies" of a version
+func getPeerDependencies(versions map[string]registry.Manifest, v string) map[string]string {
+
+	peerDependencies := map[string]string{}
+	for name, peerRange := range versions[v].PeerDependencies {
+		peerDependencies[name] = peerRange
+	}
+
+	return peerDependencies
+}
@@ -0,0 +1,28 @@
+package npm
+
+// How the updated versions are written on package.json, allowed on --pin-style
+const (
+	// Keep the prefix of the current version, locked versions stay locked
+	PinStylePreserve = "preserve"
+	// "1.2.3"
+	PinStyleExact = "exact"
+	// "^1.2.3"
+	PinStyleCaret = "caret"
+	// "~1.2.3"
+	PinStyleTilde = "tilde"
+)
+
+var PinStyles = []string{PinStylePreserve, PinStyleExact, PinStyleCaret, PinStyleTilde}
+
+// getPinPrefix returns the prefix of the updated version for the prefix of the current one
+func getPinPrefix(currentPrefix string, pinStyle string) string {
+	switch pinStyle {
+	case PinStyleExact:
+		return ""
+	case PinStyleCaret:
+		return "^"
+	case PinStyleTilde:
+		return "~"
+	}
+	return currentPrefix
+}
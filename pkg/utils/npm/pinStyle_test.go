@@ -0,0 +1,25 @@
+package npm
+
+import "testing"
+
+func TestGetPinPrefix(t *testing.T) {
+	testCases := []struct {
+		currentPrefix string
+		pinStyle      string
+		expected      string
+	}{
+		{currentPrefix: "^", pinStyle: PinStylePreserve, expected: "^"},
+		{currentPrefix: "", pinStyle: PinStylePreserve, expected: ""},
+		{currentPrefix: "", pinStyle: "", expected: ""},
+		{currentPrefix: "^", pinStyle: PinStyleExact, expected: ""},
+		{currentPrefix: "", pinStyle: PinStyleCaret, expected: "^"},
+		{currentPrefix: "^", pinStyle: PinStyleTilde, expected: "~"},
+	}
+
+	for _, tc := range testCases {
+		prefix := getPinPrefix(tc.currentPrefix, tc.pinStyle)
+		if prefix != tc.expected {
+			t.Errorf("expected %q but got %q for %q and %q", tc.expected, prefix, tc.currentPrefix, tc.pinStyle)
+		}
+	}
+}
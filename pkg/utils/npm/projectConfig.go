@@ -0,0 +1,64 @@
+package npm
+
+import (
+	"sort"
+
+	"github.com/icaruk/up-npm/pkg/utils/group"
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+// isIgnored checks if a package matches one of the ignore patterns of the project config
+func isIgnored(ignore []string, dependency string) bool {
+	for _, pattern := range ignore {
+		if group.MatchPattern(pattern, dependency) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+getCeiling returns the ceiling range of a package, an exact name wins over patterns.
+
+When several patterns match the longest one wins, like "@nestjs/core*" over "@nestjs/*", then the first alphabetically.
+*/
+func getCeiling(ceilings map[string]string, dependency string) string {
+
+	if ceiling, ok := ceilings[dependency]; ok {
+		return ceiling
+	}
+
+	patterns := make([]string, 0, len(ceilings))
+	for pattern := range ceilings {
+		patterns = append(patterns, pattern)
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	for _, pattern := range patterns {
+		if group.MatchPattern(pattern, dependency) {
+			return ceilings[pattern]
+		}
+	}
+
+	return ""
+}
+
+// getHighestVersionBelowCeiling returns the highest version up to latestVersion matching the ceiling range, "" if there is none
+func getHighestVersionBelowCeiling(versions map[string]registry.Manifest, latestVersion string, ceiling string) string {
+
+	var candidates []string
+	for v := range versions {
+		if version.Compare(v, latestVersion) <= 0 {
+			candidates = append(candidates, v)
+		}
+	}
+
+	return version.MaxSatisfying(candidates, ceiling)
+}
@@ -0,0 +1,72 @@
+package npm
+
+import (
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+func TestGetCeiling(t *testing.T) {
+	ceilings := map[string]string{
+		"@nestjs":       "^9",
+		"@nestjs/*":     "^10",
+		"@nestjs/core*": "^11",
+		"react":         "^18",
+		"react*":        "^17",
+	}
+
+	testCases := []struct {
+		dependency string
+		expected   string
+	}{
+		{dependency: "react", expected: "^18"},
+		{dependency: "react-dom", expected: "^17"},
+		{dependency: "@nestjs/core", expected: "^11"},
+		{dependency: "@nestjs/common", expected: "^10"},
+		{dependency: "vue", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dependency, func(t *testing.T) {
+			// Map iteration order changes between runs
+			for i := 0; i < 20; i++ {
+				actual := getCeiling(ceilings, tc.dependency)
+				if actual != tc.expected {
+					t.Fatalf("expected %q but got %q", tc.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestGetHighestVersionBelowCeiling(t *testing.T) {
+	versions := map[string]registry.Manifest{
+		"17.0.2":        {},
+		"18.2.0":        {},
+		"18.3.1":        {},
+		"19.0.0-rc.1":   {},
+		"19.0.0":        {},
+		"19.1.0-canary": {},
+	}
+
+	testCases := []struct {
+		ceiling  string
+		latest   string
+		expected string
+	}{
+		{ceiling: "^18", latest: "19.0.0", expected: "18.3.1"},
+		{ceiling: "<19", latest: "19.0.0", expected: "18.3.1"},
+		{ceiling: "*", latest: "19.0.0", expected: "19.0.0"},
+		{ceiling: "^18", latest: "18.2.0", expected: "18.2.0"},
+		{ceiling: "^16", latest: "19.0.0", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ceiling, func(t *testing.T) {
+			actual := getHighestVersionBelowCeiling(versions, tc.latest, tc.ceiling)
+			if actual != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -1,44 +1,41 @@
 package npm
 
 import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-)
-
-func FetchNpmRegistry(dependency string, token string) (map[string]interface{}, error) {
-
-	client := &http.Client{}
+	"strings"
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://registry.npmjs.org/%s", dependency), nil)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
 
-	if token != "" {
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
+const DefaultRegistry = "https://registry.npmjs.org"
 
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
+// GetRegistryUrl returns the configured registry without trailing slash, npm's one by default
+func GetRegistryUrl(cfg CmdFlags) string {
+	if cfg.Registry == "" {
+		return DefaultRegistry
 	}
+	return strings.TrimSuffix(cfg.Registry, "/")
+}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("package %s, status code: %d", dependency, resp.StatusCode)
+// GetPackageRegistryUrl returns the registry of the package scope from the project config, GetRegistryUrl otherwise
+func GetPackageRegistryUrl(cfg CmdFlags, dependency string) string {
+	if strings.HasPrefix(dependency, "@") {
+		scope, _, _ := strings.Cut(dependency, "/")
+		if registry, ok := cfg.Registries[scope]; ok {
+			return strings.TrimSuffix(registry, "/")
+		}
 	}
+	return GetRegistryUrl(cfg)
+}
 
-	var result map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
-
-	if err != nil {
-		return nil, err
+// getPackageToken returns the .npmrc token only when the package comes from the main registry
+func getPackageToken(cfg CmdFlags, dependency string, token string) string {
+	if GetPackageRegistryUrl(cfg, dependency) != GetRegistryUrl(cfg) {
+		return ""
 	}
+	return token
+}
 
-	return result, nil
-
+// newRegistryClient returns a client with the cache, cache mode and transport of cfg
+func newRegistryClient(cfg CmdFlags) *registry.Client {
+	return registry.NewClient(cfg.CacheDir, cfg.CacheMode(), cfg.Transport)
 }
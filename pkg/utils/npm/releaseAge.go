@@ -0,0 +1,45 @@
+package npm
+
+import (
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+// getNewestVersionReleasedBefore returns the newest stable version above currentVersion and up to maxVersion
+// released before the given date, "" if there is none
+func getNewestVersionReleasedBefore(versionTimes registry.Times, currentVersion string, maxVersion string, before time.Time) string {
+
+	current, err := version.ParseSemver(currentVersion)
+	if err != nil {
+		return ""
+	}
+
+	max, err := version.ParseSemver(maxVersion)
+	if err != nil {
+		return ""
+	}
+
+	var newest version.Semver
+	newestVersion := ""
+
+	for v, releaseDate := range versionTimes {
+		// "created" and "modified" are not versions
+		semver, err := version.ParseSemver(v)
+		if err != nil || semver.IsPrerelease() || version.CompareSemver(semver, max) > 0 || version.CompareSemver(semver, current) <= 0 {
+			continue
+		}
+
+		if !releaseDate.Before(before) {
+			continue
+		}
+
+		if newestVersion == "" || version.CompareSemver(semver, newest) > 0 {
+			newest = semver
+			newestVersion = v
+		}
+	}
+
+	return newestVersion
+}
@@ -0,0 +1,50 @@
+package npm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+func TestGetNewestVersionReleasedBefore(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	versionTimes := registry.Times{
+		"created":      date(time.January, 1),
+		"modified":     date(time.March, 10),
+		"1.0.0":        date(time.January, 1),
+		"1.1.0":        date(time.February, 1),
+		"1.2.0-beta.0": date(time.February, 15),
+		"1.2.0":        date(time.March, 1),
+		"2.0.0-rc.0":   date(time.March, 5),
+	}
+
+	testCases := []struct {
+		name           string
+		currentVersion string
+		maxVersion     string
+		before         string
+		expected       string
+	}{
+		{name: "latest is old enough", currentVersion: "1.0.0", maxVersion: "1.2.0", before: "2024-03-10T00:00:00Z", expected: "1.2.0"},
+		{name: "latest is too recent", currentVersion: "1.0.0", maxVersion: "1.2.0", before: "2024-02-20T00:00:00Z", expected: "1.1.0"},
+		{name: "versions above latest are ignored", currentVersion: "1.0.0", maxVersion: "1.1.0", before: "2024-03-10T00:00:00Z", expected: "1.1.0"},
+		{name: "every version is too recent", currentVersion: "1.0.0", maxVersion: "1.2.0", before: "2023-12-01T00:00:00Z", expected: ""},
+		{name: "current version is the newest old enough", currentVersion: "1.1.0", maxVersion: "1.2.0", before: "2024-02-20T00:00:00Z", expected: ""},
+		{name: "versions below current are ignored", currentVersion: "1.1.5", maxVersion: "1.2.0", before: "2024-02-20T00:00:00Z", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before, _ := time.Parse(time.RFC3339, tc.before)
+
+			newestVersion := getNewestVersionReleasedBefore(versionTimes, tc.currentVersion, tc.maxVersion, before)
+			if newestVersion != tc.expected {
+				t.Errorf("expected %q but got %q", tc.expected, newestVersion)
+			}
+		})
+	}
+}
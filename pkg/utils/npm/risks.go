@@ -0,0 +1,102 @@
+package npm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+var installScripts = []string{"preinstall", "install", "postinstall"}
+
+// getRiskSignals compares the registry manifests of the current and target versions and returns
+// the supply-chain risk signals introduced by the target version
+func getRiskSignals(versions map[string]registry.Manifest, currentVersion string, targetVersion string) []string {
+
+	current, hasCurrent := versions[currentVersion]
+	target, ok := versions[targetVersion]
+	if !ok {
+		return nil
+	}
+
+	var risks []string
+
+	// Install scripts
+	for _, script := range installScripts {
+		if target.Scripts[script] != "" && current.Scripts[script] == "" {
+			risks = append(risks, fmt.Sprintf("new %s script", script))
+		}
+	}
+
+	// Publisher and maintainers
+	currentPublisher := current.NpmUser.Name
+	targetPublisher := target.NpmUser.Name
+
+	if currentPublisher != "" && targetPublisher != "" && currentPublisher != targetPublisher {
+		risks = append(risks, fmt.Sprintf("published by %s instead of %s", targetPublisher, currentPublisher))
+	}
+
+	if hasCurrent {
+		added := difference(getMaintainerNames(target), getMaintainerNames(current))
+		if len(added) > 0 {
+			risks = append(risks, fmt.Sprintf("new maintainers: %s", strings.Join(added, ", ")))
+		}
+	}
+
+	// Dependencies
+	if hasCurrent {
+		added := difference(getKeys(target.Dependencies), getKeys(current.Dependencies))
+		if len(added) > 0 {
+			risks = append(risks, fmt.Sprintf("new dependencies: %s", strings.Join(added, ", ")))
+		}
+	}
+
+	// Provenance, only a signal when the current version had it
+	if hasProvenance(current) && !hasProvenance(target) {
+		risks = append(risks, "no provenance attestation")
+	}
+
+	return risks
+}
+
+func getMaintainerNames(manifest registry.Manifest) []string {
+	var names []string
+	for _, maintainer := range manifest.Maintainers {
+		if maintainer.Name != "" {
+			names = append(names, maintainer.Name)
+		}
+	}
+
+	return names
+}
+
+func hasProvenance(manifest registry.Manifest) bool {
+	return manifest.Dist.Attestations != nil && manifest.Dist.Attestations.Provenance != nil
+}
+
+func getKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// difference returns the sorted items of a that are not in b
+func difference(a []string, b []string) []string {
+	inB := map[string]bool{}
+	for _, item := range b {
+		inB[item] = true
+	}
+
+	var result []string
+	for _, item := range a {
+		if !inB[item] {
+			result = append(result, item)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,70 @@
+package npm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+)
+
+func TestGetRiskSignals(t *testing.T) {
+	provenance := json.RawMessage(`{}`)
+
+	versions := map[string]registry.Manifest{
+		"1.0.0": {
+			NpmUser:      registry.Person{Name: "alice"},
+			Maintainers:  []registry.Person{{Name: "alice"}},
+			Dependencies: registry.StringMap{"debug": "^4.0.0"},
+			Scripts:      registry.StringMap{"test": "jest"},
+			Dist: registry.Dist{
+				Attestations: &registry.Attestations{Provenance: &provenance},
+			},
+		},
+		"1.1.0": {
+			NpmUser:      registry.Person{Name: "alice"},
+			Maintainers:  []registry.Person{{Name: "alice"}},
+			Dependencies: registry.StringMap{"debug": "^4.1.0"},
+			Dist: registry.Dist{
+				Attestations: &registry.Attestations{Provenance: &provenance},
+			},
+		},
+		"2.0.0": {
+			NpmUser:      registry.Person{Name: "mallory"},
+			Maintainers:  []registry.Person{{Name: "alice"}, {Name: "mallory"}},
+			Dependencies: registry.StringMap{"debug": "^4.1.0", "crypto-miner": "^1.0.0"},
+			Scripts:      registry.StringMap{"postinstall": "node setup.js"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		current  string
+		target   string
+		expected []string
+	}{
+		{name: "no risks", current: "1.0.0", target: "1.1.0", expected: nil},
+		{
+			name:    "every risk",
+			current: "1.0.0",
+			target:  "2.0.0",
+			expected: []string{
+				"new postinstall script",
+				"published by mallory instead of alice",
+				"new maintainers: mallory",
+				"new dependencies: crypto-miner",
+				"no provenance attestation",
+			},
+		},
+		{name: "unknown target", current: "1.0.0", target: "3.0.0", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			risks := getRiskSignals(versions, tc.current, tc.target)
+			if !reflect.DeepEqual(risks, tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, risks)
+			}
+		})
+	}
+}
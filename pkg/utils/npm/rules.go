@@ -0,0 +1,53 @@
+package npm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/config"
+	"github.com/icaruk/up-npm/pkg/utils/group"
+)
+
+// findRule returns the first rule of the project config matching the package that has not expired
+func findRule(rules []config.Rule, dependency string, now time.Time) (config.Rule, bool) {
+	for _, rule := range rules {
+		if rule.IsExpired(now) {
+			continue
+		}
+		if group.MatchPattern(rule.Name, dependency) {
+			return rule, true
+		}
+	}
+	return config.Rule{}, false
+}
+
+// GetExpiredRules returns the rules whose date has passed
+func GetExpiredRules(rules []config.Rule) []config.Rule {
+	now := time.Now()
+
+	var expired []config.Rule
+	for _, rule := range rules {
+		if rule.IsExpired(now) {
+			expired = append(expired, rule)
+		}
+	}
+	return expired
+}
+
+// getRuleMessage describes why a rule holds back a package for the summary
+func getRuleMessage(rule config.Rule, latestVersion string) string {
+
+	message := "ignored"
+	if rule.MaxVersion != "" {
+		message = fmt.Sprintf("%s held back to %s", latestVersion, rule.MaxVersion)
+	}
+
+	if rule.Reason != "" {
+		message += ": " + rule.Reason
+	}
+	if rule.Until != "" {
+		message += fmt.Sprintf(" (until %s)", rule.Until)
+	}
+
+	return message
+}
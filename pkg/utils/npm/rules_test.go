@@ -0,0 +1,41 @@
+package npm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/config"
+)
+
+func TestFindRule(t *testing.T) {
+	rules := []config.Rule{
+		{Name: "typescript", MaxVersion: "5.4.x", Reason: "angular compat", Until: "2026-12-01"},
+		{Name: "typescript", MaxVersion: "5.5.x"},
+		{Name: "@types"},
+	}
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	rule, ok := findRule(rules, "typescript", now)
+	if !ok || rule.MaxVersion != "5.4.x" {
+		t.Errorf("expected the 5.4.x rule but got %+v, %v", rule, ok)
+	}
+
+	// Once expired, the next matching rule applies
+	rule, ok = findRule(rules, "typescript", now.AddDate(0, 3, 0))
+	if !ok || rule.MaxVersion != "5.5.x" {
+		t.Errorf("expected the 5.5.x rule after expiration but got %+v, %v", rule, ok)
+	}
+
+	if rule, ok := findRule(rules, "@types/node", now); !ok || rule.Name != "@types" {
+		t.Errorf("expected the @types rule but got %+v, %v", rule, ok)
+	}
+	if _, ok := findRule(rules, "react", now); ok {
+		t.Error("expected no rule for react")
+	}
+
+	expectedMessage := "5.6.2 held back to 5.4.x: angular compat (until 2026-12-01)"
+	if message := getRuleMessage(rules[0], "5.6.2"); message != expectedMessage {
+		t.Errorf("expected %q but got %q", expectedMessage, message)
+	}
+}
@@ -0,0 +1,44 @@
+package npm
+
+import (
+	"sync"
+
+	"github.com/icaruk/up-npm/pkg/utils/registry"
+	"github.com/icaruk/up-npm/pkg/utils/signature"
+)
+
+type registryKeysResult struct {
+	keys []signature.Key
+	err  error
+}
+
+// Registry keys are fetched once per registry
+var registryKeysCache = map[string]registryKeysResult{}
+var registryKeysMutex sync.Mutex
+
+// getRegistryKeys returns the signing keys of the registry the package comes from
+func getRegistryKeys(client *registry.Client, registryUrl string, token string) ([]signature.Key, error) {
+	registryKeysMutex.Lock()
+	defer registryKeysMutex.Unlock()
+
+	result, ok := registryKeysCache[registryUrl]
+	if !ok {
+		result.keys, result.err = client.GetKeys(registryUrl, token)
+		registryKeysCache[registryUrl] = result
+	}
+
+	return result.keys, result.err
+}
+
+// verifyVersionSignature verifies the registry signatures of a version using its "dist" field
+func verifyVersionSignature(keys []signature.Key, dependency string, versions map[string]registry.Manifest, v string, versionTimes registry.Times) error {
+
+	dist := versions[v].Dist
+
+	var signatures []signature.Signature
+	for _, sig := range dist.Signatures {
+		signatures = append(signatures, signature.Signature{KeyId: sig.KeyId, Sig: sig.Sig})
+	}
+
+	return signature.Verify(keys, dependency, v, dist.Integrity, signatures, versionTimes[v])
+}
@@ -0,0 +1,128 @@
+package npmrc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NetworkConfig has the .npmrc settings needed behind corporate proxies and custom certificate authorities
+type NetworkConfig struct {
+	// "proxy" for http requests, "https-proxy" for https ones, the HTTP_PROXY like env vars if empty
+	Proxy      string
+	HttpsProxy string
+	// Comma separated domains that don't use the proxy
+	NoProxy string
+	// PEM certificates trusted besides the system ones, from "ca" and the "cafile" path
+	Ca     []string
+	CaFile string
+	// Certificates are not verified if false
+	StrictSSL bool
+	// PEM client certificate and key
+	Cert string
+	Key  string
+}
+
+// GetNetworkConfig reads the network settings from the .npmrc config
+func GetNetworkConfig(config map[string]string) NetworkConfig {
+
+	networkConfig := NetworkConfig{
+		Proxy:      config["proxy"],
+		HttpsProxy: config["https-proxy"],
+		NoProxy:    config["noproxy"],
+		CaFile:     config["cafile"],
+		StrictSSL:  config["strict-ssl"] != "false",
+		Cert:       unescapePem(config["cert"]),
+		Key:        unescapePem(config["key"]),
+	}
+
+	// "ca[]" has one certificate per line
+	cas := append([]string{config["ca"]}, strings.Split(config["ca[]"], "\n")...)
+	for _, ca := range cas {
+		if ca := unescapePem(ca); ca != "" {
+			networkConfig.Ca = append(networkConfig.Ca, ca)
+		}
+	}
+
+	return networkConfig
+}
+
+// PEM values are written in one line with "\n" escapes
+func unescapePem(value string) string {
+	return strings.ReplaceAll(value, `\n`, "\n")
+}
+
+// NewTransport returns a copy of the default transport using the proxies and TLS settings of the config
+func (c NetworkConfig) NewTransport() (*http.Transport, error) {
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxyConfig := httpproxy.FromEnvironment()
+	if c.Proxy != "" {
+		proxyConfig.HTTPProxy = c.Proxy
+		proxyConfig.HTTPSProxy = c.Proxy
+	}
+	if c.HttpsProxy != "" {
+		proxyConfig.HTTPSProxy = c.HttpsProxy
+	}
+	if c.NoProxy != "" {
+		proxyConfig.NoProxy = c.NoProxy
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+
+	if !c.StrictSSL {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	ca := c.Ca
+	if c.CaFile != "" {
+		fileContent, err := os.ReadFile(c.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("cafile: %w", err)
+		}
+		ca = append(ca, string(fileContent))
+	}
+
+	if len(ca) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, certificate := range ca {
+			if !pool.AppendCertsFromPEM([]byte(certificate)) {
+				return nil, errors.New("ca: no valid PEM certificate found")
+			}
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.Cert != "" || c.Key != "" {
+		certificate, err := tls.X509KeyPair([]byte(c.Cert), []byte(c.Key))
+		if err != nil {
+			return nil, fmt.Errorf("cert/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
@@ -0,0 +1,140 @@
+package npmrc
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestGetNetworkConfig(t *testing.T) {
+	config := ParseNpmrcConfig(`
+proxy=http://proxy.corp:8080
+https-proxy=http://secure-proxy.corp:8080
+noproxy=localhost,.corp
+strict-ssl=false
+ca="-----BEGIN CERTIFICATE-----\nABC\n-----END CERTIFICATE-----"
+cafile=/etc/ssl/corp.pem
+`)
+
+	expected := NetworkConfig{
+		Proxy:      "http://proxy.corp:8080",
+		HttpsProxy: "http://secure-proxy.corp:8080",
+		NoProxy:    "localhost,.corp",
+		Ca:         []string{"-----BEGIN CERTIFICATE-----\nABC\n-----END CERTIFICATE-----"},
+		CaFile:     "/etc/ssl/corp.pem",
+		StrictSSL:  false,
+	}
+
+	networkConfig := GetNetworkConfig(config)
+	if !reflect.DeepEqual(networkConfig, expected) {
+		t.Errorf("expected %+v but got %+v", expected, networkConfig)
+	}
+
+	if !GetNetworkConfig(map[string]string{}).StrictSSL {
+		t.Errorf("expected strict-ssl to be true by default")
+	}
+}
+
+func TestGetNetworkConfigCaArray(t *testing.T) {
+	config := ParseNpmrcConfig(`
+ca[]="-----BEGIN CERTIFICATE-----\nROOT\n-----END CERTIFICATE-----"
+ca[]="-----BEGIN CERTIFICATE-----\nINTERMEDIATE\n-----END CERTIFICATE-----"
+`)
+
+	expected := []string{
+		"-----BEGIN CERTIFICATE-----\nROOT\n-----END CERTIFICATE-----",
+		"-----BEGIN CERTIFICATE-----\nINTERMEDIATE\n-----END CERTIFICATE-----",
+	}
+
+	ca := GetNetworkConfig(config).Ca
+	if !reflect.DeepEqual(ca, expected) {
+		t.Errorf("expected %q but got %q", expected, ca)
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	for _, env := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+		t.Setenv(env, "")
+	}
+
+	transport, err := NetworkConfig{
+		Proxy:      "http://proxy.corp:8080",
+		HttpsProxy: "http://secure-proxy.corp:8080",
+		NoProxy:    ".internal",
+		StrictSSL:  true,
+	}.NewTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{url: "http://registry.npmjs.org/react", expected: "http://proxy.corp:8080"},
+		{url: "https://registry.npmjs.org/react", expected: "http://secure-proxy.corp:8080"},
+		{url: "https://npm.internal/react", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		req, _ := http.NewRequest("GET", tc.url, nil)
+
+		proxy, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		proxyUrl := ""
+		if proxy != nil {
+			proxyUrl = proxy.String()
+		}
+		if proxyUrl != tc.expected {
+			t.Errorf("expected proxy %q but got %q for %s", tc.expected, proxyUrl, tc.url)
+		}
+	}
+}
+
+func TestNewTransportTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	serverCa := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	testCases := []struct {
+		name          string
+		config        NetworkConfig
+		expectedError bool
+	}{
+		{name: "unknown authority", config: NetworkConfig{StrictSSL: true}, expectedError: true},
+		{name: "custom ca", config: NetworkConfig{StrictSSL: true, Ca: []string{serverCa}}, expectedError: false},
+		{name: "strict-ssl disabled", config: NetworkConfig{StrictSSL: false}, expectedError: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport, err := tc.config.NewTransport()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			serverUrl, _ := url.Parse(server.URL)
+			req := &http.Request{Method: "GET", URL: serverUrl, Header: http.Header{}}
+
+			resp, err := transport.RoundTrip(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			if (err != nil) != tc.expectedError {
+				t.Errorf("expected error %v but got %v", tc.expectedError, err)
+			}
+		})
+	}
+
+	if _, err := (NetworkConfig{Ca: []string{"not a certificate"}}).NewTransport(); err == nil {
+		t.Errorf("expected an error for an invalid ca")
+	}
+}
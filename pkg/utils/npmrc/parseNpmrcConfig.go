@@ -0,0 +1,84 @@
+package npmrc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ParseNpmrcConfig parses the "key=value" settings of a .npmrc file.
+Repeated "key[]=value" array settings are joined with new lines, values are one line.
+
+Example:
+
+	# comment
+	engine-strict=true
+	//registry.npmjs.org/:_authToken=npm_ABCdef123456
+*/
+func ParseNpmrcConfig(str string) map[string]string {
+
+	config := map[string]string{}
+
+	for _, line := range strings.Split(str, "\n") {
+
+		cleanLine := strings.TrimSpace(line)
+
+		// Skip empty lines and comments
+		if cleanLine == "" || strings.HasPrefix(cleanLine, "#") || strings.HasPrefix(cleanLine, ";") {
+			continue
+		}
+
+		key, value, found := strings.Cut(cleanLine, "=")
+		if !found {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		// Quoted values
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if strings.HasSuffix(key, "[]") && config[key] != "" {
+			value = config[key] + "\n" + value
+		}
+
+		config[key] = value
+	}
+
+	return config
+}
+
+// GetNpmrcConfig returns the settings of the per-user and per-project .npmrc files, the per-project ones take precedence
+func GetNpmrcConfig() map[string]string {
+
+	config := map[string]string{}
+
+	var files []string
+
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		files = append(files, filepath.Join(homeDir, npmrcFilename))
+	}
+	files = append(files, npmrcFilename)
+
+	for _, file := range files {
+		fileContent, err := os.ReadFile(file)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		for key, value := range ParseNpmrcConfig(string(fileContent)) {
+			config[key] = value
+		}
+	}
+
+	return config
+}
@@ -0,0 +1,27 @@
+package npmrc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNpmrcConfig(t *testing.T) {
+	npmrcContent := `
+# comment
+; comment
+engine-strict=true
+registry = "https://npm.example.com/"
+//registry.npmjs.org/:_authToken=npm_1234
+invalid line
+`
+
+	expected := map[string]string{
+		"engine-strict":                    "true",
+		"registry":                         "https://npm.example.com/",
+		"//registry.npmjs.org/:_authToken": "npm_1234",
+	}
+
+	if config := ParseNpmrcConfig(npmrcContent); !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected %v but got %v", expected, config)
+	}
+}
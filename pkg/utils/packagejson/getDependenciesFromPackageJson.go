@@ -16,6 +16,7 @@ type PackageJSON struct {
 	Homepage        string            `json:"homepage"`
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
+	Engines         map[string]string `json:"engines"`
 }
 
 func GetDependenciesFromPackageJson(
@@ -0,0 +1,110 @@
+package packagejson
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type InstallStrategy string
+
+const (
+	InstallTargeted     InstallStrategy = "targeted"
+	InstallFull         InstallStrategy = "full"
+	InstallLockfileOnly InstallStrategy = "lockfile"
+	InstallNone         InstallStrategy = "none"
+)
+
+var InstallStrategies = []InstallStrategy{
+	InstallTargeted,
+	InstallFull,
+	InstallLockfileOnly,
+	InstallNone,
+}
+
+// ParseInstallStrategy validates an install strategy coming from flags, empty means "ask the user"
+func ParseInstallStrategy(strategy string) (InstallStrategy, error) {
+	if strategy == "" {
+		return "", nil
+	}
+
+	for _, s := range InstallStrategies {
+		if string(s) == strategy {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid install strategy \"%s\", allowed values are: targeted, full, lockfile, none", strategy)
+}
+
+/*
+GetTargetedInstallationCommands returns the commands that install only the given package specs (name@version),
+one for runtime dependencies and another one for dev dependencies.
+
+exact saves the versions without range, the package managers write them with "^" otherwise.
+*/
+func GetTargetedInstallationCommands(packageManager string, dependencies []string, devDependencies []string, exact bool) []string {
+
+	var baseCommand string
+	exactFlag := "--exact"
+
+	switch packageManager {
+	case "pnpm":
+		baseCommand = "pnpm add"
+		exactFlag = "--save-exact"
+	case "yarn":
+		baseCommand = "yarn add"
+	case "bun":
+		baseCommand = "bun add"
+	default:
+		baseCommand = "npm install"
+		exactFlag = "--save-exact"
+	}
+
+	if exact {
+		baseCommand = fmt.Sprintf("%s %s", baseCommand, exactFlag)
+	}
+
+	var commands []string
+
+	if len(dependencies) > 0 {
+		commands = append(commands, fmt.Sprintf("%s %s", baseCommand, strings.Join(dependencies, " ")))
+	}
+	if len(devDependencies) > 0 {
+		commands = append(commands, fmt.Sprintf("%s -D %s", baseCommand, strings.Join(devDependencies, " ")))
+	}
+
+	return commands
+}
+
+// GetLockfileOnlyCommand returns the command that updates the lockfile without touching node_modules
+func GetLockfileOnlyCommand(packageManager string) string {
+	switch packageManager {
+	case "npm":
+		return "npm install --package-lock-only"
+	case "pnpm":
+		return "pnpm install --lockfile-only"
+	case "yarn":
+		// Yarn 1 has no way of updating only the lockfile
+		if IsYarnClassic() {
+			return ""
+		}
+		return "yarn install --mode update-lockfile"
+	case "bun":
+		return "bun install --lockfile-only"
+	default:
+		return "npm install --package-lock-only"
+	}
+}
+
+// SupportsLockfileOnly checks if the package manager can update the lockfile without installing
+func SupportsLockfileOnly(packageManager string) bool {
+	return GetLockfileOnlyCommand(packageManager) != ""
+}
+
+// IsYarnClassic checks if yarn.lock was written by Yarn 1, Yarn 2+ lockfiles are YAML without this header
+func IsYarnClassic() bool {
+	content, err := os.ReadFile(GetLockfileName("yarn"))
+	return err == nil && bytes.Contains(content, []byte("# yarn lockfile v1"))
+}
@@ -0,0 +1,50 @@
+package packagejson
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+/*
+GetNodeVersion returns the lowest Node version the project runs on, looking in order at
+.nvmrc, .node-version and the "engines.node" field of package.json next to packageJsonFilename.
+
+Returns "" when none of them has a usable version (like "lts/*").
+*/
+func GetNodeVersion(packageJsonFilename string) (nodeVersion string, source string) {
+
+	dir := filepath.Dir(packageJsonFilename)
+
+	for _, filename := range []string{".nvmrc", ".node-version"} {
+		fileContent, err := os.ReadFile(filepath.Join(dir, filename))
+		if err != nil {
+			continue
+		}
+
+		if nodeVersion := version.MinVersion(strings.TrimSpace(string(fileContent))); nodeVersion != "" {
+			return nodeVersion, filename
+		}
+	}
+
+	jsonFile, err := os.ReadFile(packageJsonFilename)
+	if err != nil {
+		return "", ""
+	}
+
+	var packageJsonMap PackageJSON
+	if err := json.Unmarshal(jsonFile, &packageJsonMap); err != nil {
+		return "", ""
+	}
+
+	if engine := packageJsonMap.Engines["node"]; engine != "" {
+		if nodeVersion := version.MinVersion(engine); nodeVersion != "" {
+			return nodeVersion, "package.json engines"
+		}
+	}
+
+	return "", ""
+}
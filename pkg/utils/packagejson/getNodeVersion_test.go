@@ -0,0 +1,65 @@
+package packagejson
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetNodeVersion(t *testing.T) {
+	testCases := []struct {
+		name           string
+		files          map[string]string
+		expected       string
+		expectedSource string
+	}{
+		{
+			name: ".nvmrc first",
+			files: map[string]string{
+				".nvmrc":        "v18.17.1\n",
+				".node-version": "20",
+				"package.json":  `{"engines": {"node": ">=16"}}`,
+			},
+			expected:       "18.17.1",
+			expectedSource: ".nvmrc",
+		},
+		{
+			name: "lts alias is ignored",
+			files: map[string]string{
+				".nvmrc":        "lts/hydrogen",
+				".node-version": "20",
+			},
+			expected:       "20.0.0",
+			expectedSource: ".node-version",
+		},
+		{
+			name: "package.json engines",
+			files: map[string]string{
+				"package.json": `{"engines": {"node": "^16.14.0 || >=18"}}`,
+			},
+			expected:       "16.14.0",
+			expectedSource: "package.json engines",
+		},
+		{
+			name:     "nothing",
+			files:    map[string]string{"package.json": `{}`},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			nodeVersion, source := GetNodeVersion(filepath.Join(dir, "package.json"))
+			if nodeVersion != tc.expected || source != tc.expectedSource {
+				t.Errorf("expected %q, %q but got %q, %q", tc.expected, tc.expectedSource, nodeVersion, source)
+			}
+		})
+	}
+}
@@ -52,3 +52,18 @@ func GetInstallationCommand(packageManager string) string {
 		return "npm install"
 	}
 }
+
+func GetLockfileName(packageManager string) string {
+	switch packageManager {
+	case "npm":
+		return "package-lock.json"
+	case "pnpm":
+		return "pnpm-lock.yaml"
+	case "yarn":
+		return "yarn.lock"
+	case "bun":
+		return "bun.lockb"
+	default:
+		return "package-lock.json"
+	}
+}
@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+/*
+Packument is the registry document of a package.
+
+The abbreviated document only has Name, Modified, DistTags and the install fields of Versions,
+Time, Homepage and Repository are empty on it.
+
+Registry documents are old and loosely validated, so fields with unexpected shapes are left empty
+instead of failing the whole document.
+*/
+type Packument struct {
+	Name       string              `json:"name"`
+	Modified   string              `json:"modified"`
+	DistTags   StringMap           `json:"dist-tags"`
+	Versions   map[string]Manifest `json:"versions"`
+	Time       Times               `json:"time"`
+	Homepage   String              `json:"homepage"`
+	Repository Repository          `json:"repository"`
+}
+
+// Latest returns the "latest" dist-tag
+func (p Packument) Latest() string {
+	return p.DistTags["latest"]
+}
+
+// Manifest is the package.json of a version as stored by the registry
+type Manifest struct {
+	Name             string    `json:"name"`
+	Version          string    `json:"version"`
+	Deprecated       String    `json:"deprecated"`
+	Engines          StringMap `json:"engines"`
+	Dependencies     StringMap `json:"dependencies"`
+	PeerDependencies StringMap `json:"peerDependencies"`
+	Scripts          StringMap `json:"scripts"`
+	License          License   `json:"license"`
+	// Legacy list of licenses, any of them applies
+	Licenses    []License `json:"licenses"`
+	NpmUser     Person    `json:"_npmUser"`
+	Maintainers []Person  `json:"maintainers"`
+	Dist        Dist      `json:"dist"`
+	// Only on the abbreviated document
+	HasInstallScript bool `json:"hasInstallScript"`
+}
+
+type Dist struct {
+	Integrity    string          `json:"integrity"`
+	Tarball      string          `json:"tarball"`
+	Signatures   []DistSignature `json:"signatures"`
+	Attestations *Attestations   `json:"attestations"`
+}
+
+type DistSignature struct {
+	KeyId string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type Attestations struct {
+	Url        string           `json:"url"`
+	Provenance *json.RawMessage `json:"provenance"`
+}
+
+// String is a string field that is empty when the registry has something else, like `"deprecated": false`
+type String string
+
+func (s *String) UnmarshalJSON(data []byte) error {
+	var value string
+	if json.Unmarshal(data, &value) == nil {
+		*s = String(value)
+	}
+	return nil
+}
+
+// StringMap keeps the string values of an object, like "dependencies", ignoring the rest
+type StringMap map[string]string
+
+func (m *StringMap) UnmarshalJSON(data []byte) error {
+	var values map[string]any
+	if json.Unmarshal(data, &values) != nil {
+		return nil
+	}
+
+	result := StringMap{}
+	for key, value := range values {
+		if valueSt, ok := value.(string); ok {
+			result[key] = valueSt
+		}
+	}
+
+	*m = result
+	return nil
+}
+
+// Times are the release dates of the "time" field by version, "created" and "modified" included
+type Times map[string]time.Time
+
+func (t *Times) UnmarshalJSON(data []byte) error {
+	var values StringMap
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	result := Times{}
+	for key, value := range values {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			result[key] = parsed
+		}
+	}
+
+	*t = result
+	return nil
+}
+
+// License is a SPDX expression, from a string or a legacy {"type": "MIT", "url": "..."} object
+type License string
+
+func (l *License) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Type String `json:"type"`
+	}
+
+	var valueSt String
+	if err := json.Unmarshal(data, &valueSt); err == nil && valueSt != "" {
+		*l = License(valueSt)
+	} else if json.Unmarshal(data, &value) == nil {
+		*l = License(value.Type)
+	}
+
+	return nil
+}
+
+// Person is a {"name": "...", "email": "..."} field or a "Name <email> (url)" string
+type Person struct {
+	Name  string
+	Email string
+}
+
+func (p *Person) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Name  String `json:"name"`
+		Email String `json:"email"`
+	}
+
+	var valueSt String
+	if err := json.Unmarshal(data, &valueSt); err == nil && valueSt != "" {
+		name, rest, _ := strings.Cut(string(valueSt), "<")
+		email, _, _ := strings.Cut(rest, ">")
+		*p = Person{Name: strings.TrimSpace(name), Email: strings.TrimSpace(email)}
+	} else if json.Unmarshal(data, &value) == nil {
+		*p = Person{Name: string(value.Name), Email: string(value.Email)}
+	}
+
+	return nil
+}
+
+// Repository is a {"type": "git", "url": "..."} field or a "github:user/repo" string
+type Repository struct {
+	Type      string
+	Url       string
+	Directory string
+}
+
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Type      String `json:"type"`
+		Url       String `json:"url"`
+		Directory String `json:"directory"`
+	}
+
+	var valueSt String
+	if err := json.Unmarshal(data, &valueSt); err == nil && valueSt != "" {
+		*r = Repository{Url: string(valueSt)}
+	} else if json.Unmarshal(data, &value) == nil {
+		*r = Repository{Type: string(value.Type), Url: string(value.Url), Directory: string(value.Directory)}
+	}
+
+	return nil
+}
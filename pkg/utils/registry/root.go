@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/httpcache"
+	"github.com/icaruk/up-npm/pkg/utils/signature"
+)
+
+const (
+	// Timeout of each attempt
+	DefaultRequestTimeout = 30 * time.Second
+	// Timeout of a whole fetch, retries included
+	DefaultTimeout = 2 * time.Minute
+	DefaultRetries = 3
+)
+
+// Accept header of the abbreviated ("corgi") document, falling back to the full one on registries without it
+const abbreviatedAccept = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8, */*"
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized, check the .npmrc token")
+	ErrRateLimited  = errors.New("rate limited by the registry")
+)
+
+// Error is a failed fetch of a package, it unwraps to ErrNotFound, ErrUnauthorized, ErrRateLimited or the cause
+type Error struct {
+	Package    string
+	StatusCode int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("package %s, %s", e.Package, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Client fetches registry documents through the on-disk cache, sharing the connections between requests
+type Client struct {
+	cache     *httpcache.Cache
+	cacheMode httpcache.Mode
+}
+
+// NewClient returns a client caching the documents in cacheDir, nothing is cached if empty.
+// transport has the proxy and TLS settings, http.DefaultTransport is used if nil
+func NewClient(cacheDir string, cacheMode httpcache.Mode, transport *http.Transport) *Client {
+	return newClient(cacheDir, cacheMode, &retryTransport{
+		base:           NewTransport(transport),
+		retries:        DefaultRetries,
+		baseDelay:      500 * time.Millisecond,
+		requestTimeout: DefaultRequestTimeout,
+	}, DefaultTimeout)
+}
+
+func newClient(cacheDir string, cacheMode httpcache.Mode, transport http.RoundTripper, timeout time.Duration) *Client {
+	cache := httpcache.New(cacheDir)
+	cache.Client = &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+
+	return &Client{
+		cache:     cache,
+		cacheMode: cacheMode,
+	}
+}
+
+// NewTransport returns a copy of base (http.DefaultTransport if nil) keeping enough idle connections for concurrent fetches
+// to the same registry
+func NewTransport(base *http.Transport) *http.Transport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport := base.Clone()
+	transport.MaxIdleConnsPerHost = 32
+	return transport
+}
+
+// GetPackument fetches the full document of a package, with "time", "homepage", "repository" and every manifest field
+func (c *Client) GetPackument(registry string, name string, token string) (Packument, error) {
+	return c.getPackument(registry, name, token, "application/json")
+}
+
+// GetAbbreviatedPackument fetches the much smaller document with the install fields only
+func (c *Client) GetAbbreviatedPackument(registry string, name string, token string) (Packument, error) {
+	return c.getPackument(registry, name, token, abbreviatedAccept)
+}
+
+// GetKeys fetches the public keys the registry signs the packages with, from /-/npm/v1/keys
+func (c *Client) GetKeys(registry string, token string) ([]signature.Key, error) {
+
+	body, err := c.get(fmt.Sprintf("%s/-/npm/v1/keys", strings.TrimSuffix(registry, "/")), token, "application/json")
+	if err != nil {
+		return nil, newError("-/npm/v1/keys", err)
+	}
+
+	var result struct {
+		Keys []signature.Key `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, &Error{Package: "-/npm/v1/keys", Err: err}
+	}
+
+	return result.Keys, nil
+}
+
+func (c *Client) get(url string, token string, accept string) ([]byte, error) {
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	return c.cache.Get(req, c.cacheMode)
+}
+
+func (c *Client) getPackument(registry string, name string, token string, accept string) (Packument, error) {
+
+	body, err := c.get(fmt.Sprintf("%s/%s", strings.TrimSuffix(registry, "/"), name), token, accept)
+	if err != nil {
+		return Packument{}, newError(name, err)
+	}
+
+	var packument Packument
+
+	// Fields with an unexpected type are skipped, the rest of the document is still usable
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(body, &packument); err != nil && !errors.As(err, &typeErr) {
+		return Packument{}, &Error{Package: name, Err: err}
+	}
+
+	return packument, nil
+}
+
+func newError(name string, err error) *Error {
+
+	var statusErr *httpcache.StatusError
+	if !errors.As(err, &statusErr) {
+		return &Error{Package: name, Err: err}
+	}
+
+	result := &Error{Package: name, StatusCode: statusErr.StatusCode, Err: err}
+
+	switch statusErr.StatusCode {
+	case http.StatusNotFound:
+		result.Err = ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		result.Err = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		result.Err = ErrRateLimited
+	}
+
+	return result
+}
@@ -0,0 +1,236 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/icaruk/up-npm/pkg/utils/httpcache"
+)
+
+const fixturePackument = `{
+	"name": "left-pad",
+	"dist-tags": {"latest": "1.3.0"},
+	"versions": {
+		"1.2.0": {
+			"version": "1.2.0",
+			"deprecated": false,
+			"engines": ["node >= 0.4"],
+			"license": {"type": "MIT", "url": "https://opensource.org/licenses/MIT"},
+			"_npmUser": "alice <alice@example.com>"
+		},
+		"1.3.0": {
+			"version": "1.3.0",
+			"deprecated": "use String.prototype.padStart()",
+			"engines": {"node": ">=4"},
+			"license": "WTFPL",
+			"dependencies": {"debug": "^4.0.0", "broken": 1},
+			"_npmUser": {"name": "alice", "email": "alice@example.com"},
+			"dist": {"integrity": "sha512-abc", "signatures": [{"keyid": "SHA256:abc", "sig": "MEUC"}]}
+		}
+	},
+	"time": {
+		"created": "2016-01-01T00:00:00.000Z",
+		"1.3.0": "2018-04-09T01:25:49.436Z",
+		"unpublished": {"time": "2018-05-01T00:00:00.000Z"}
+	},
+	"homepage": ["https://example.com"],
+	"repository": "github:left-pad/left-pad"
+}`
+
+func TestGetPackument(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/left-pad" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(fixturePackument))
+	}))
+	defer server.Close()
+
+	packument, err := NewClient("", httpcache.ModeDefault, nil).GetPackument(server.URL, "left-pad", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if packument.Latest() != "1.3.0" {
+		t.Errorf("expected latest %q but got %q", "1.3.0", packument.Latest())
+	}
+
+	old := packument.Versions["1.2.0"]
+	latest := packument.Versions["1.3.0"]
+
+	testCases := []struct {
+		name     string
+		actual   any
+		expected any
+	}{
+		{name: "deprecated false", actual: old.Deprecated, expected: String("")},
+		{name: "deprecated message", actual: latest.Deprecated, expected: String("use String.prototype.padStart()")},
+		{name: "engines array", actual: len(old.Engines), expected: 0},
+		{name: "engines object", actual: latest.Engines["node"], expected: ">=4"},
+		{name: "license object", actual: old.License, expected: License("MIT")},
+		{name: "license string", actual: latest.License, expected: License("WTFPL")},
+		{name: "non string dependency", actual: len(latest.Dependencies), expected: 1},
+		{name: "publisher string", actual: old.NpmUser.Name, expected: "alice"},
+		{name: "publisher object", actual: latest.NpmUser.Name, expected: "alice"},
+		{name: "signatures", actual: latest.Dist.Signatures[0].KeyId, expected: "SHA256:abc"},
+		{name: "release time", actual: packument.Time["1.3.0"].Year(), expected: 2018},
+		{name: "unpublished time", actual: len(packument.Time), expected: 2},
+		{name: "homepage array", actual: packument.Homepage, expected: String("")},
+		{name: "repository string", actual: packument.Repository.Url, expected: "github:left-pad/left-pad"},
+	}
+
+	for _, tc := range testCases {
+		if tc.actual != tc.expected {
+			t.Errorf("expected %v but got %v for %s", tc.expected, tc.actual, tc.name)
+		}
+	}
+}
+
+func TestGetPackumentErrors(t *testing.T) {
+
+	testCases := []struct {
+		name       string
+		statusCode int
+		expected   error
+	}{
+		{name: "not found", statusCode: http.StatusNotFound, expected: ErrNotFound},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, expected: ErrUnauthorized},
+		{name: "forbidden", statusCode: http.StatusForbidden, expected: ErrUnauthorized},
+		{name: "rate limited", statusCode: http.StatusTooManyRequests, expected: ErrRateLimited},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			_, err := NewClient("", httpcache.ModeDefault, nil).GetPackument(server.URL, "left-pad", "")
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v but got %v", tc.expected, err)
+			}
+
+			var registryErr *Error
+			if !errors.As(err, &registryErr) || registryErr.StatusCode != tc.statusCode {
+				t.Errorf("expected status code %d but got %#v", tc.statusCode, err)
+			}
+		})
+	}
+}
+
+func TestGetPackumentRetries(t *testing.T) {
+
+	testCases := []struct {
+		name string
+		// Responses before succeeding
+		failures         []int
+		slowFirstAttempt bool
+		expectedError    bool
+		expectedRequests int32
+	}{
+		{name: "no retries needed", expectedRequests: 1},
+		{name: "retries server errors", failures: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, expectedRequests: 3},
+		{name: "retries rate limits", failures: []int{http.StatusTooManyRequests}, expectedRequests: 2},
+		{name: "gives up", failures: []int{500, 500, 500}, expectedError: true, expectedRequests: 3},
+		{name: "client errors are not retried", failures: []int{http.StatusBadRequest}, expectedError: true, expectedRequests: 1},
+		{name: "retries timeouts", slowFirstAttempt: true, expectedRequests: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&requests, 1))
+
+				if tc.slowFirstAttempt && n == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+
+				if n <= len(tc.failures) {
+					w.WriteHeader(tc.failures[n-1])
+					return
+				}
+
+				w.Write([]byte(fixturePackument))
+			}))
+			defer server.Close()
+
+			// Without cache nor retry delays
+			client := newClient("", "", &retryTransport{
+				base:           NewTransport(nil),
+				retries:        2,
+				baseDelay:      time.Millisecond,
+				requestTimeout: 100 * time.Millisecond,
+			}, 5*time.Second)
+
+			_, err := client.GetAbbreviatedPackument(server.URL, "left-pad", "")
+
+			if (err != nil) != tc.expectedError {
+				t.Errorf("expected error %v but got %v", tc.expectedError, err)
+			}
+			if actual := atomic.LoadInt32(&requests); actual != tc.expectedRequests {
+				t.Errorf("expected %d requests but got %d", tc.expectedRequests, actual)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		header     string
+		expected   time.Duration
+		expectedOk bool
+	}{
+		{header: "", expected: 0, expectedOk: false},
+		{header: "5", expected: 5 * time.Second, expectedOk: true},
+		{header: "3600", expected: maxRetryAfter, expectedOk: true},
+		{header: "Mon, 01 Jan 2024 00:00:10 GMT", expected: 10 * time.Second, expectedOk: true},
+		{header: "Sun, 31 Dec 2023 00:00:00 GMT", expected: 0, expectedOk: true},
+		{header: "soon", expected: 0, expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		delay, ok := parseRetryAfter(tc.header, now)
+		if delay != tc.expected || ok != tc.expectedOk {
+			t.Errorf("expected %v, %v but got %v, %v for %q", tc.expected, tc.expectedOk, delay, ok, tc.header)
+		}
+	}
+}
+
+func TestGetKeys(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/npm/v1/keys" || r.Header.Get("Authorization") != "Bearer scope-token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"keys": [{"keyid": "SHA256:abc", "keytype": "ecdsa-sha2-nistp256", "key": "MFkw"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("", httpcache.ModeDefault, nil)
+
+	keys, err := client.GetKeys(server.URL+"/", "scope-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].KeyId != "SHA256:abc" {
+		t.Errorf("expected key SHA256:abc but got %+v", keys)
+	}
+
+	if _, err := client.GetKeys(server.URL, "main-token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v but got %v", ErrNotFound, err)
+	}
+}
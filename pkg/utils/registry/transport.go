@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Longest Retry-After we are willing to wait
+const maxRetryAfter = 30 * time.Second
+
+// retryTransport retries the requests that fail or get a 429 or 5xx, each attempt with its own timeout
+type retryTransport struct {
+	base           http.RoundTripper
+	retries        int
+	baseDelay      time.Duration
+	requestTimeout time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(req.Context(), t.requestTimeout)
+
+		resp, err := t.base.RoundTrip(req.Clone(ctx))
+
+		isLastAttempt := attempt >= t.retries || req.Context().Err() != nil
+
+		if err == nil && (!isRetryableStatus(resp.StatusCode) || isLastAttempt) {
+			// The attempt timeout must last until the body is read
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := t.getDelay(attempt)
+
+		if err == nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = retryAfter
+			}
+
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		cancel()
+
+		if isLastAttempt {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// getDelay returns the exponential backoff of an attempt, with jitter so concurrent requests don't retry at once
+func (t *retryTransport) getDelay(attempt int) time.Duration {
+	delay := t.baseDelay << attempt
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or as a HTTP date, capped to maxRetryAfter
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+
+	if header == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = date.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	return min(delay, maxRetryAfter), true
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
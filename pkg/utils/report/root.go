@@ -0,0 +1,166 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+type Package struct {
+	Name              string
+	From              string
+	To                string
+	VersionType       version.UpgradeType
+	IsDev             bool
+	HoursSinceRelease float64
+	ReleaseNotesUrl   string
+	// Why the package has not been updated, skipped packages only
+	Reason string
+}
+
+// Step is a command run during the session, empty Command means it has not been run
+type Step struct {
+	Command string
+	Err     error
+}
+
+type Report struct {
+	File       string
+	Updated    []Package
+	Skipped    []Package
+	Install    Step
+	Verify     Step
+	RolledBack bool
+	Commits    []string
+}
+
+// Generate builds a markdown summary of the update session ready to be pasted on a merge request
+func Generate(r Report) string {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Dependency updates\n\n")
+	fmt.Fprintf(&b, "Updated **%d** package(s) in `%s`.\n", len(r.Updated), r.File)
+
+	for _, versionType := range []version.UpgradeType{version.Major, version.Minor, version.Patch} {
+
+		var packages []Package
+		for _, pkg := range r.Updated {
+			if pkg.VersionType == versionType {
+				packages = append(packages, pkg)
+			}
+		}
+
+		if len(packages) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", capitalize(string(versionType)), len(packages))
+		fmt.Fprintf(&b, "| Package | From | To | Released | Release notes |\n")
+		fmt.Fprintf(&b, "|---|---|---|---|---|\n")
+
+		for _, pkg := range packages {
+			fmt.Fprintf(
+				&b,
+				"| %s | %s | %s | %s | %s |\n",
+				formatPackageName(pkg),
+				pkg.From,
+				pkg.To,
+				FormatReleaseAge(pkg.HoursSinceRelease),
+				formatLink(pkg.ReleaseNotesUrl),
+			)
+		}
+	}
+
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(&b, "\n## Skipped (%d)\n\n", len(r.Skipped))
+		fmt.Fprintf(&b, "| Package | Current | Latest | Reason |\n")
+		fmt.Fprintf(&b, "|---|---|---|---|\n")
+
+		for _, pkg := range r.Skipped {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", formatPackageName(pkg), pkg.From, pkg.To, escapeCell(pkg.Reason))
+		}
+	}
+
+	if len(r.Commits) > 0 {
+		fmt.Fprintf(&b, "\n## Commits\n\n")
+		for _, commit := range r.Commits {
+			fmt.Fprintf(&b, "- %s\n", commit)
+		}
+	}
+
+	if r.Install.Command != "" || r.Verify.Command != "" {
+		fmt.Fprintf(&b, "\n## Result\n\n")
+
+		if r.Install.Command != "" {
+			fmt.Fprintf(&b, "- Install `%s`: %s\n", r.Install.Command, formatStepResult(r.Install))
+		}
+		if r.Verify.Command != "" {
+			fmt.Fprintf(&b, "- Verification `%s`: %s\n", r.Verify.Command, formatStepResult(r.Verify))
+		}
+		if r.RolledBack {
+			fmt.Fprintf(&b, "- `%s` and lockfile have been restored from backup\n", r.File)
+		}
+	}
+
+	return b.String()
+}
+
+// FormatReleaseAge converts hours since the release into a readable age like "5 hours ago" or "3 days ago"
+func FormatReleaseAge(hours float64) string {
+
+	if hours < 0 {
+		return "-"
+	}
+
+	if hours < 1 {
+		return "less than an hour ago"
+	}
+
+	if hours < 48 {
+		return fmt.Sprintf("%d hours ago", int(math.Round(hours)))
+	}
+
+	days := int(math.Round(hours / 24))
+	if days < 60 {
+		return fmt.Sprintf("%d days ago", days)
+	}
+
+	return fmt.Sprintf("%d months ago", int(math.Round(float64(days)/30)))
+}
+
+func formatPackageName(pkg Package) string {
+	if pkg.IsDev {
+		return fmt.Sprintf("`%s` (dev)", pkg.Name)
+	}
+	return fmt.Sprintf("`%s`", pkg.Name)
+}
+
+func formatLink(url string) string {
+	if url == "" {
+		return "-"
+	}
+	return fmt.Sprintf("[link](%s)", url)
+}
+
+// escapeCell keeps text like error messages inside its table cell
+func escapeCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func formatStepResult(step Step) string {
+	if step.Err != nil {
+		return fmt.Sprintf("❌ failed (%s)", step.Err)
+	}
+	return "✅ passed"
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/icaruk/up-npm/pkg/utils/version"
+)
+
+func TestFormatReleaseAge(t *testing.T) {
+	testCases := []struct {
+		hours    float64
+		expected string
+	}{
+		{hours: -1, expected: "-"},
+		{hours: 0.5, expected: "less than an hour ago"},
+		{hours: 5.2, expected: "5 hours ago"},
+		{hours: 47, expected: "47 hours ago"},
+		{hours: 72, expected: "3 days ago"},
+		{hours: 24 * 200, expected: "7 months ago"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			age := FormatReleaseAge(tc.hours)
+			if age != tc.expected {
+				t.Errorf("expected %v but got %v for %v hours", tc.expected, age, tc.hours)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	markdown := Generate(Report{
+		File: "package.json",
+		Updated: []Package{
+			{Name: "react", From: "18.3.1", To: "19.0.0", VersionType: version.Major, HoursSinceRelease: 72, ReleaseNotesUrl: "https://github.com/facebook/react/releases"},
+			{Name: "lodash", From: "4.17.20", To: "4.17.21", VersionType: version.Patch, IsDev: true, HoursSinceRelease: 5},
+		},
+		Skipped: []Package{
+			{Name: "axios", From: "1.6.0", To: "1.7.2", VersionType: version.Minor, Reason: "skipped"},
+			{Name: "vite", From: "5.0.0", To: "6.0.0", VersionType: version.Major, Reason: "peer conflict: react ^18 | ^19\nrequired"},
+		},
+		Install: Step{Command: "npm install react@^19.0.0"},
+		Verify:  Step{Command: "npm test", Err: errors.New("exit status 1")},
+	})
+
+	expectedLines := []string{
+		"Updated **2** package(s) in `package.json`.",
+		"## Major (1)",
+		"| `react` | 18.3.1 | 19.0.0 | 3 days ago | [link](https://github.com/facebook/react/releases) |",
+		"## Patch (1)",
+		"| `lodash` (dev) | 4.17.20 | 4.17.21 | 5 hours ago | - |",
+		"## Skipped (2)",
+		"| `axios` | 1.6.0 | 1.7.2 | skipped |",
+		"| `vite` | 5.0.0 | 6.0.0 | peer conflict: react ^18 \\| ^19 required |",
+		"- Install `npm install react@^19.0.0`: ✅ passed",
+		"- Verification `npm test`: ❌ failed (exit status 1)",
+	}
+
+	for _, line := range expectedLines {
+		if !strings.Contains(markdown, line) {
+			t.Errorf("expected report to contain %q\n%s", line, markdown)
+		}
+	}
+
+	if strings.Contains(markdown, "## Minor") {
+		t.Errorf("expected report without minor section\n%s", markdown)
+	}
+}
@@ -75,12 +75,12 @@ func GetRepositoryUrlMetadata(url string) URLMetadata {
 /*
 Get latest release from a given repository
 */
-func FetchRepositoryLatestRelease(user string, repository string) (map[string]interface{}, error) {
+func FetchRepositoryLatestRelease(client *http.Client, user string, repository string) (map[string]interface{}, error) {
 
 	// Build URL like https://api.github.com/repos/<user>/<repository>/releases
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", user, repository)
 
-	res, err := http.Get(url)
+	res, err := client.Get(url)
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
@@ -111,12 +111,12 @@ func FetchRepositoryLatestRelease(user string, repository string) (map[string]in
 /*
 Get CHANGELOG.md file from a given repository
 */
-func FetchRepositoryChangelogFile(user string, repository string) (map[string]interface{}, error) {
+func FetchRepositoryChangelogFile(client *http.Client, user string, repository string) (map[string]interface{}, error) {
 
 	// Build URL like https://api.github.com/repos/<user>/<repository>/contents/CHANGELOG.md
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/CHANGELOG.md", user, repository)
 
-	res, err := http.Get(url)
+	res, err := client.Get(url)
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
@@ -143,3 +143,43 @@ func FetchRepositoryChangelogFile(user string, repository string) (map[string]in
 	return decodedBody, nil
 
 }
+
+type ReleaseNotesSource string
+
+const (
+	ReleaseNotesGithubReleases ReleaseNotesSource = "releases"
+	ReleaseNotesChangelog      ReleaseNotesSource = "changelog"
+	ReleaseNotesHomepage       ReleaseNotesSource = "homepage"
+	ReleaseNotesNone           ReleaseNotesSource = ""
+)
+
+/*
+Get the best URL to read what changed since the current version: github releases, CHANGELOG.md or homepage
+*/
+func GetReleaseNotesUrl(client *http.Client, repositoryUrl string, currentVersion string, homepage string) (string, ReleaseNotesSource) {
+
+	if repositoryUrl != "" {
+		// Get user and repository from repository URL
+		urlMetadata := GetRepositoryUrlMetadata(repositoryUrl)
+
+		// Fetch repository from github
+		_, err := FetchRepositoryLatestRelease(client, urlMetadata.Username, urlMetadata.RepositoryName)
+		if err == nil {
+			return repositoryUrl + "/releases" + "#:~:text=" + currentVersion, ReleaseNotesGithubReleases
+		}
+
+		// Fetch CHANGELOG.md
+		response, err := FetchRepositoryChangelogFile(client, urlMetadata.Username, urlMetadata.RepositoryName)
+		if err == nil {
+			if changelogMdUrl, ok := response["html_url"].(string); ok && changelogMdUrl != "" {
+				return changelogMdUrl, ReleaseNotesChangelog
+			}
+		}
+	}
+
+	if homepage != "" {
+		return homepage, ReleaseNotesHomepage
+	}
+
+	return "", ReleaseNotesNone
+}
@@ -0,0 +1,110 @@
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing registry signature")
+	ErrUnknownKey       = errors.New("signed with an unknown registry key")
+	ErrExpiredKey       = errors.New("signed with an expired registry key")
+	ErrInvalidSignature = errors.New("invalid registry signature")
+)
+
+// Key is a public key of the registry used to sign the packages
+type Key struct {
+	KeyId   string     `json:"keyid"`
+	KeyType string     `json:"keytype"`
+	Scheme  string     `json:"scheme"`
+	Key     string     `json:"key"`
+	Expires *time.Time `json:"expires"`
+}
+
+// Signature is an item of the "dist.signatures" field of a package version
+type Signature struct {
+	KeyId string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+/*
+Verify checks the registry signatures of a package version.
+
+The signed message is "<name>@<version>:<dist.integrity>", a signature is only valid if its key
+had not expired when the version was published.
+*/
+func Verify(keys []Key, name string, version string, integrity string, signatures []Signature, publishedAt time.Time) error {
+
+	if len(signatures) == 0 || integrity == "" {
+		return ErrMissingSignature
+	}
+
+	message := fmt.Sprintf("%s@%s:%s", name, version, integrity)
+	digest := sha256.Sum256([]byte(message))
+
+	// Any valid signature is enough
+	err := ErrUnknownKey
+
+	for _, signature := range signatures {
+		key, ok := findKey(keys, signature.KeyId)
+		if !ok {
+			continue
+		}
+
+		if key.Expires != nil && !publishedAt.IsZero() && publishedAt.After(*key.Expires) {
+			err = ErrExpiredKey
+			continue
+		}
+
+		publicKey, parseErr := parseKey(key)
+		if parseErr != nil {
+			err = parseErr
+			continue
+		}
+
+		sig, decodeErr := base64.StdEncoding.DecodeString(signature.Sig)
+		if decodeErr != nil || !ecdsa.VerifyASN1(publicKey, digest[:], sig) {
+			err = ErrInvalidSignature
+			continue
+		}
+
+		return nil
+	}
+
+	return err
+}
+
+func findKey(keys []Key, keyId string) (Key, bool) {
+	for _, key := range keys {
+		if key.KeyId == keyId {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// parseKey decodes the base64 DER public key of the registry, only ECDSA keys are supported
+func parseKey(key Key) (*ecdsa.PublicKey, error) {
+
+	der, err := base64.StdEncoding.DecodeString(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("registry key %s: %w", key.KeyId, err)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("registry key %s: %w", key.KeyId, err)
+	}
+
+	ecdsaKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("registry key %s: unsupported key type %s", key.KeyId, key.KeyType)
+	}
+
+	return ecdsaKey, nil
+}
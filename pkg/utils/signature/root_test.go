@@ -0,0 +1,101 @@
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+const integrity = "sha512-abc"
+
+// newFixtureKey returns the registry key of a new ECDSA key pair, with its private key to sign
+func newFixtureKey(t *testing.T, expires *time.Time) (Key, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := Key{
+		KeyId:   "SHA256:fixture",
+		KeyType: "ecdsa-sha2-nistp256",
+		Scheme:  "ecdsa-sha2-nistp256",
+		Key:     base64.StdEncoding.EncodeToString(der),
+		Expires: expires,
+	}
+
+	return key, privateKey
+}
+
+func sign(t *testing.T, privateKey *ecdsa.PrivateKey, message string) Signature {
+	t.Helper()
+
+	digest := sha256.Sum256([]byte(message))
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Signature{KeyId: "SHA256:fixture", Sig: base64.StdEncoding.EncodeToString(sig)}
+}
+
+func TestVerify(t *testing.T) {
+	key, privateKey := newFixtureKey(t, nil)
+
+	testCases := []struct {
+		name       string
+		signatures []Signature
+		expected   error
+	}{
+		{name: "valid", signatures: []Signature{sign(t, privateKey, "lodash@4.17.21:"+integrity)}, expected: nil},
+		{name: "other version", signatures: []Signature{sign(t, privateKey, "lodash@4.17.20:"+integrity)}, expected: ErrInvalidSignature},
+		{name: "unknown key", signatures: []Signature{{KeyId: "SHA256:other", Sig: "c2ln"}}, expected: ErrUnknownKey},
+		{name: "missing", signatures: nil, expected: ErrMissingSignature},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify([]Key{key}, "lodash", "4.17.21", integrity, tc.signatures, time.Now())
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestVerifyExpiredKey(t *testing.T) {
+	expires := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	key, privateKey := newFixtureKey(t, &expires)
+	signatures := []Signature{sign(t, privateKey, "lodash@4.17.21:"+integrity)}
+
+	testCases := []struct {
+		name        string
+		publishedAt time.Time
+		expected    error
+	}{
+		{name: "published before expiration", publishedAt: expires.Add(-time.Hour), expected: nil},
+		{name: "published after expiration", publishedAt: expires.Add(time.Hour), expected: ErrExpiredKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify([]Key{key}, "lodash", "4.17.21", integrity, signatures, tc.publishedAt)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package version
+
+import (
+	"github.com/icaruk/up-npm/pkg/utils/advisory"
+)
+
+// Severity returns the highest severity of the advisories affecting the current version
+func (item VersionComparisonItem) Severity() string {
+	return advisory.HighestSeverity(item.Advisories)
+}
+
+// IsVulnerable checks if the current version is affected by any advisory
+func (item VersionComparisonItem) IsVulnerable() bool {
+	return len(item.Advisories) > 0
+}
+
+// IsFixedBy checks if version is outside of the vulnerable range of the advisory
+func IsFixedBy(a advisory.Advisory, version string) bool {
+	affected, err := Satisfies(version, a.VulnerableVersions)
+	return err == nil && !affected
+}
+
+// LatestFixesAdvisories checks if updating to Latest fixes at least one advisory of the current version
+func (item VersionComparisonItem) LatestFixesAdvisories() bool {
+	for _, a := range item.Advisories {
+		if IsFixedBy(a, item.Latest) {
+			return true
+		}
+	}
+	return false
+}
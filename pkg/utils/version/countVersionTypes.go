@@ -1,15 +1,54 @@
 package version
 
+import "github.com/icaruk/up-npm/pkg/utils/advisory"
+
 type VersionComparisonItem struct {
-	Current              string
-	Latest               string
-	VersionType          UpgradeType
-	ShouldUpdate         bool
-	Homepage             string
-	RepositoryUrl        string
-	VersionPrefix        string
-	IsDev                bool
+	Current       string
+	Latest        string
+	VersionType   UpgradeType
+	ShouldUpdate  bool
+	Homepage      string
+	RepositoryUrl string
+	// Prefix of the updated version on package.json, "" for an exact version
+	VersionPrefix string
+	// The current version is locked to an exact version
+	IsLocked bool
+	IsDev    bool
+	// -1 when the registry has no release date for Latest, the age is then not shown
 	HoursSinceLasRelease float64
+	// Advisories affecting the current version
+	Advisories []advisory.Advisory
+	// Deprecation messages, empty when not deprecated
+	CurrentDeprecated string
+	LatestDeprecated  string
+	// Newest version that is not deprecated, only when Latest is deprecated
+	SuggestedVersion string
+	// Newest version skipped because it was released more recently than --min-release-age
+	TooRecentVersion string
+	// Supply-chain risk signals of Latest compared to Current, like "new postinstall script"
+	Risks []string
+	// Why the registry signature of Latest could not be verified, only with --verify-signatures
+	SignatureError string
+	// SPDX license expressions from the registry
+	CurrentLicense string
+	LatestLicense  string
+	// "engines.node" of Latest when it does not support the project Node version
+	RequiredNode string
+	// Newest version supporting the project Node version, only when RequiredNode is set
+	NodeCompatibleVersion string
+	// "peerDependencies" of Latest
+	PeerDependencies map[string]string
+	// "peerDependencies" of NodeCompatibleVersion, they replace PeerDependencies when updating to it
+	NodeCompatiblePeerDependencies map[string]string
+	// Name of the update group, packages of the same group are updated together
+	Group string
+	// Why a rule of the project config holds back Latest
+	HeldReason string
+}
+
+// LicenseChanged checks if Latest has a different license than Current
+func (item VersionComparisonItem) LicenseChanged() bool {
+	return item.CurrentLicense != item.LatestLicense
 }
 
 func CountVersionTypes(
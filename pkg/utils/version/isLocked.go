@@ -0,0 +1,14 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+)
+
+var lockedVersionRegex = regexp.MustCompile(`^[=v]?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// IsLocked checks if a package.json version is pinned to an exact version, like "1.2.3" or "=1.2.3".
+// Partial versions like "1.2" are ranges, they are not locked.
+func IsLocked(version string) bool {
+	return lockedVersionRegex.MatchString(strings.TrimSpace(version))
+}
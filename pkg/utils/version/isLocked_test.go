@@ -0,0 +1,32 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestIsLocked(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected bool
+	}{
+		{version: "1.2.3", expected: true},
+		{version: "=1.2.3", expected: true},
+		{version: "v1.2.3", expected: true},
+		{version: "15.0.0-canary.102", expected: true},
+		{version: "^1.2.3", expected: false},
+		{version: "~1.2.3", expected: false},
+		{version: ">=1.2.3", expected: false},
+		{version: "1.2", expected: false},
+		{version: "1.2.x", expected: false},
+		{version: "*", expected: false},
+		{version: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			if isLocked := IsLocked(tc.version); isLocked != tc.expected {
+				t.Errorf("expected %v but got %v for %q", tc.expected, isLocked, tc.version)
+			}
+		})
+	}
+}
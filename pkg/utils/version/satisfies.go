@@ -0,0 +1,364 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type comparator struct {
+	operator string // <, <=, >, >=, =
+	version  Semver
+}
+
+func (c comparator) test(v Semver) bool {
+	cmp := CompareSemver(v, c.version)
+
+	switch c.operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+
+	return cmp == 0
+}
+
+// Every comparator must match, like ">=1.2.3 <2.0.0-0"
+type comparatorSet []comparator
+
+func (set comparatorSet) test(v Semver) bool {
+
+	for _, c := range set {
+		if !c.test(v) {
+			return false
+		}
+	}
+
+	// Prereleases only match if a comparator has a prerelease on the same major.minor.patch
+	if v.IsPrerelease() {
+		for _, c := range set {
+			if c.version.IsPrerelease() &&
+				c.version.Major == v.Major && c.version.Minor == v.Minor && c.version.Patch == v.Patch {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// partialVersion is a version that may have wildcards like "1.2.x", "1" or "*"
+type partialVersion struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease []string
+	// how many of major, minor and patch have been specified
+	parts int
+}
+
+var operatorSpacesRegex = regexp.MustCompile(`(<=|>=|<|>|=|~|\^)\s+`)
+var hyphenRangeRegex = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+var comparatorRegex = regexp.MustCompile(`^(<=|>=|<|>|=|~|\^)?(.*)$`)
+
+// Satisfies checks if version matches a npm semver range like "^1.2.3", ">=1.0.0 <2.0.0 || 3.x" or "1.2.3 - 2.0.0"
+func Satisfies(version string, semverRange string) (bool, error) {
+
+	v, err := ParseSemver(version)
+	if err != nil {
+		return false, err
+	}
+
+	sets, err := parseRange(semverRange)
+	if err != nil {
+		return false, err
+	}
+
+	for _, set := range sets {
+		if set.test(v) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// MaxSatisfying returns the highest of versions matching the range, or "" if none does
+func MaxSatisfying(versions []string, semverRange string) string {
+
+	sets, err := parseRange(semverRange)
+	if err != nil {
+		return ""
+	}
+
+	var best Semver
+	bestVersion := ""
+
+	for _, version := range versions {
+		v, err := ParseSemver(version)
+		if err != nil {
+			continue
+		}
+
+		for _, set := range sets {
+			if !set.test(v) {
+				continue
+			}
+
+			if bestVersion == "" || CompareSemver(v, best) > 0 {
+				best = v
+				bestVersion = version
+			}
+			break
+		}
+	}
+
+	return bestVersion
+}
+
+// MinVersion returns the lowest version matching the range, like "18.0.0" for ">=18" or "18", "" if the range is invalid
+func MinVersion(semverRange string) string {
+
+	sets, err := parseRange(semverRange)
+	if err != nil {
+		return ""
+	}
+
+	var min Semver
+	minVersion := ""
+
+	for _, set := range sets {
+		lower := newSemver(0, 0, 0)
+
+		for _, c := range set {
+			candidate := c.version
+
+			switch c.operator {
+			case ">":
+				candidate = newSemver(c.version.Major, c.version.Minor, c.version.Patch+1)
+			case "<", "<=":
+				continue
+			}
+
+			if CompareSemver(candidate, lower) > 0 {
+				lower = candidate
+			}
+		}
+
+		if !set.test(lower) {
+			continue
+		}
+
+		if minVersion == "" || CompareSemver(lower, min) < 0 {
+			min = lower
+			minVersion = lower.String()
+		}
+	}
+
+	return minVersion
+}
+
+func parseRange(semverRange string) ([]comparatorSet, error) {
+
+	r := strings.TrimSpace(semverRange)
+	r = strings.ReplaceAll(r, "~>", "~")
+	r = operatorSpacesRegex.ReplaceAllString(r, "$1")
+
+	var sets []comparatorSet
+
+	for _, part := range strings.Split(r, "||") {
+		part = strings.TrimSpace(part)
+
+		set := comparatorSet{}
+
+		if matches := hyphenRangeRegex.FindStringSubmatch(part); matches != nil {
+			from, err := parsePartialVersion(matches[1])
+			if err != nil {
+				return nil, err
+			}
+			to, err := parsePartialVersion(matches[2])
+			if err != nil {
+				return nil, err
+			}
+
+			set = append(set, desugarComparator(">=", from)...)
+			set = append(set, desugarComparator("<=", to)...)
+
+			sets = append(sets, set)
+			continue
+		}
+
+		for _, field := range strings.Fields(part) {
+			matches := comparatorRegex.FindStringSubmatch(field)
+
+			partial, err := parsePartialVersion(matches[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid range \"%s\": %w", semverRange, err)
+			}
+
+			set = append(set, desugarComparator(matches[1], partial)...)
+		}
+
+		sets = append(sets, set)
+	}
+
+	return sets, nil
+}
+
+func parsePartialVersion(version string) (partialVersion, error) {
+
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+
+	// Drop build metadata
+	if i := strings.Index(v, "+"); i != -1 {
+		v = v[:i]
+	}
+
+	var partial partialVersion
+
+	if i := strings.Index(v, "-"); i != -1 {
+		partial.prerelease = strings.Split(v[i+1:], ".")
+		v = v[:i]
+	}
+
+	if v == "" {
+		return partial, nil
+	}
+
+	numbers := []*int{&partial.major, &partial.minor, &partial.patch}
+
+	for i, part := range strings.Split(v, ".") {
+		if i > 2 {
+			return partial, fmt.Errorf("invalid version \"%s\"", version)
+		}
+
+		// Wildcards end the version
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return partial, fmt.Errorf("invalid version \"%s\"", version)
+		}
+
+		*numbers[i] = n
+		partial.parts++
+	}
+
+	// A prerelease only makes sense on a full version
+	if partial.parts < 3 {
+		partial.prerelease = nil
+	}
+
+	return partial, nil
+}
+
+func newSemver(major int, minor int, patch int, prerelease ...string) Semver {
+	return Semver{Major: major, Minor: minor, Patch: patch, Prerelease: prerelease}
+}
+
+// desugarComparator converts "~1.2", "^0.2.3", "1.x", ">1.2"... into plain comparators
+func desugarComparator(operator string, p partialVersion) comparatorSet {
+
+	full := Semver{Major: p.major, Minor: p.minor, Patch: p.patch, Prerelease: p.prerelease}
+	nextMajor := newSemver(p.major+1, 0, 0, "0")
+	nextMinor := newSemver(p.major, p.minor+1, 0, "0")
+
+	// Wildcard ranges like "1.x" and "1.2"
+	xRange := func() comparatorSet {
+		switch p.parts {
+		case 0:
+			return comparatorSet{}
+		case 1:
+			return comparatorSet{{">=", newSemver(p.major, 0, 0)}, {"<", nextMajor}}
+		}
+		return comparatorSet{{">=", newSemver(p.major, p.minor, 0)}, {"<", nextMinor}}
+	}
+
+	switch operator {
+
+	case "~":
+		if p.parts < 3 {
+			return xRange()
+		}
+		return comparatorSet{{">=", full}, {"<", nextMinor}}
+
+	case "^":
+		switch p.parts {
+		case 0, 1:
+			return xRange()
+		case 2:
+			if p.major > 0 {
+				return comparatorSet{{">=", newSemver(p.major, p.minor, 0)}, {"<", nextMajor}}
+			}
+			return xRange()
+		}
+
+		switch {
+		case p.major > 0:
+			return comparatorSet{{">=", full}, {"<", nextMajor}}
+		case p.minor > 0:
+			return comparatorSet{{">=", full}, {"<", nextMinor}}
+		}
+		return comparatorSet{{">=", full}, {"<", newSemver(p.major, p.minor, p.patch+1, "0")}}
+
+	case ">":
+		switch p.parts {
+		case 0:
+			// Nothing is greater than everything
+			return comparatorSet{{"<", newSemver(0, 0, 0, "0")}}
+		case 1:
+			return comparatorSet{{">=", newSemver(p.major+1, 0, 0)}}
+		case 2:
+			return comparatorSet{{">=", newSemver(p.major, p.minor+1, 0)}}
+		}
+		return comparatorSet{{">", full}}
+
+	case ">=":
+		switch p.parts {
+		case 0:
+			return comparatorSet{}
+		case 1:
+			return comparatorSet{{">=", newSemver(p.major, 0, 0)}}
+		case 2:
+			return comparatorSet{{">=", newSemver(p.major, p.minor, 0)}}
+		}
+		return comparatorSet{{">=", full}}
+
+	case "<":
+		switch p.parts {
+		case 0:
+			return comparatorSet{{"<", newSemver(0, 0, 0, "0")}}
+		case 1:
+			return comparatorSet{{"<", newSemver(p.major, 0, 0, "0")}}
+		case 2:
+			return comparatorSet{{"<", newSemver(p.major, p.minor, 0, "0")}}
+		}
+		return comparatorSet{{"<", full}}
+
+	case "<=":
+		switch p.parts {
+		case 0:
+			return comparatorSet{}
+		case 1:
+			return comparatorSet{{"<", nextMajor}}
+		case 2:
+			return comparatorSet{{"<", nextMinor}}
+		}
+		return comparatorSet{{"<=", full}}
+	}
+
+	// "=" or no operator
+	if p.parts < 3 {
+		return xRange()
+	}
+	return comparatorSet{{"=", full}}
+}
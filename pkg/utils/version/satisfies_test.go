@@ -0,0 +1,140 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestSatisfies(t *testing.T) {
+	testCases := []struct {
+		version  string
+		rng      string
+		expected bool
+	}{
+		{version: "1.2.3", rng: "^1.2.3", expected: true},
+		{version: "1.9.0", rng: "^1.2.3", expected: true},
+		{version: "2.0.0", rng: "^1.2.3", expected: false},
+		{version: "1.2.2", rng: "^1.2.3", expected: false},
+		{version: "0.2.5", rng: "^0.2.3", expected: true},
+		{version: "0.3.0", rng: "^0.2.3", expected: false},
+		{version: "0.0.3", rng: "^0.0.3", expected: true},
+		{version: "0.0.4", rng: "^0.0.3", expected: false},
+		{version: "1.2.9", rng: "~1.2.3", expected: true},
+		{version: "1.3.0", rng: "~1.2.3", expected: false},
+		{version: "1.5.0", rng: "~1", expected: true},
+		{version: "5.4.5", rng: "5.4.x", expected: true},
+		{version: "5.5.0", rng: "5.4.x", expected: false},
+		{version: "3.0.0", rng: "*", expected: true},
+		{version: "3.0.0", rng: "", expected: true},
+		{version: "4.17.20", rng: "<4.17.21", expected: true},
+		{version: "4.17.21", rng: "<4.17.21", expected: false},
+		{version: "2.1.0", rng: ">=2.0.0 <2.1.3", expected: true},
+		{version: "2.1.3", rng: ">=2.0.0 <2.1.3", expected: false},
+		{version: "3.0.0", rng: ">=2.0.0 <2.1.3 || >=3.0.0 <3.0.1", expected: true},
+		{version: "1.5.0", rng: "1.2.3 - 2.3", expected: true},
+		{version: "2.3.9", rng: "1.2.3 - 2.3", expected: true},
+		{version: "2.4.0", rng: "1.2.3 - 2.3", expected: false},
+		{version: "18.17.0", rng: ">= 18", expected: true},
+		{version: "16.20.0", rng: ">=18", expected: false},
+		{version: "18.0.0", rng: "^16.14.0 || >=18.0.0", expected: true},
+		{version: "1.3.0", rng: ">1.2", expected: true},
+		{version: "1.2.9", rng: ">1.2", expected: false},
+		{version: "1.2.9", rng: "<=1.2", expected: true},
+		{version: "1.3.0", rng: "<=1.2", expected: false},
+		{version: "1.2.3-beta.2", rng: "^1.2.3-beta.1", expected: true},
+		{version: "1.3.0-beta.1", rng: "^1.2.3-beta.1", expected: false},
+		{version: "2.0.0-rc.1", rng: "^1.2.3", expected: false},
+		{version: "1.2.3", rng: "=1.2.3", expected: true},
+		{version: "v1.2.3", rng: "1.2.3", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version+" "+tc.rng, func(t *testing.T) {
+			satisfies, err := Satisfies(tc.version, tc.rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if satisfies != tc.expected {
+				t.Errorf("expected %v but got %v for %v and %v", tc.expected, satisfies, tc.version, tc.rng)
+			}
+		})
+	}
+}
+
+func TestSatisfiesInvalidRange(t *testing.T) {
+	_, err := Satisfies("1.0.0", "latest")
+	if err == nil {
+		t.Errorf("expected error for invalid range")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "1.0.0", b: "1.0.0", expected: 0},
+		{a: "1.0.0", b: "1.0.1", expected: -1},
+		{a: "1.10.0", b: "1.9.0", expected: 1},
+		{a: "1.0.0-alpha", b: "1.0.0", expected: -1},
+		{a: "1.0.0-alpha", b: "1.0.0-alpha.1", expected: -1},
+		{a: "1.0.0-alpha.1", b: "1.0.0-alpha.beta", expected: -1},
+		{a: "1.0.0-beta.11", b: "1.0.0-beta.2", expected: 1},
+		{a: "1.0.0-rc.1", b: "1.0.0-beta.11", expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			result := Compare(tc.a, tc.b)
+			if result != tc.expected {
+				t.Errorf("expected %v but got %v for %v and %v", tc.expected, result, tc.a, tc.b)
+			}
+		})
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []string{"5.3.3", "5.4.0", "5.4.5", "5.5.0-beta", "5.5.2", "6.0.0-dev.1"}
+
+	testCases := []struct {
+		rng      string
+		expected string
+	}{
+		{rng: "5.4.x", expected: "5.4.5"},
+		{rng: "*", expected: "5.5.2"},
+		{rng: "<5.4.0", expected: "5.3.3"},
+		{rng: ">=7", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rng, func(t *testing.T) {
+			result := MaxSatisfying(versions, tc.rng)
+			if result != tc.expected {
+				t.Errorf("expected %v but got %v for %v", tc.expected, result, tc.rng)
+			}
+		})
+	}
+}
+
+func TestMinVersion(t *testing.T) {
+	testCases := []struct {
+		rng      string
+		expected string
+	}{
+		{rng: "18", expected: "18.0.0"},
+		{rng: "v18.17.1", expected: "18.17.1"},
+		{rng: ">=18", expected: "18.0.0"},
+		{rng: "^16.14.0 || >=18", expected: "16.14.0"},
+		{rng: ">16.0.0", expected: "16.0.1"},
+		{rng: "<20", expected: "0.0.0"},
+		{rng: "lts/hydrogen", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rng, func(t *testing.T) {
+			if result := MinVersion(tc.rng); result != tc.expected {
+				t.Errorf("expected %v but got %v for %v", tc.expected, result, tc.rng)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a parsed "major.minor.patch-prerelease" version, build metadata is dropped
+type Semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+}
+
+// ParseSemver parses a full semver like "1.2.3", "v1.2.3" or "1.2.3-beta.1+build"
+func ParseSemver(version string) (Semver, error) {
+
+	v := strings.TrimSpace(version)
+	v = strings.TrimPrefix(v, "=")
+	v = strings.TrimPrefix(v, "v")
+
+	// Drop build metadata
+	if i := strings.Index(v, "+"); i != -1 {
+		v = v[:i]
+	}
+
+	var semver Semver
+
+	core := v
+	if i := strings.Index(v, "-"); i != -1 {
+		core = v[:i]
+		semver.Prerelease = strings.Split(v[i+1:], ".")
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Semver{}, fmt.Errorf("invalid version \"%s\"", version)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Semver{}, fmt.Errorf("invalid version \"%s\"", version)
+		}
+		numbers[i] = n
+	}
+
+	semver.Major, semver.Minor, semver.Patch = numbers[0], numbers[1], numbers[2]
+
+	return semver, nil
+}
+
+func (s Semver) String() string {
+	v := fmt.Sprintf("%d.%d.%d", s.Major, s.Minor, s.Patch)
+	if len(s.Prerelease) > 0 {
+		v += "-" + strings.Join(s.Prerelease, ".")
+	}
+	return v
+}
+
+// IsPrerelease checks if the version has a prerelease tag like "-beta.1"
+func (s Semver) IsPrerelease() bool {
+	return len(s.Prerelease) > 0
+}
+
+// CompareSemver returns -1, 0 or 1 if a is lower, equal or greater than b following semver precedence
+func CompareSemver(a Semver, b Semver) int {
+
+	for _, diff := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+
+	// A version without prerelease has higher precedence
+	if len(a.Prerelease) == 0 && len(b.Prerelease) == 0 {
+		return 0
+	}
+	if len(a.Prerelease) == 0 {
+		return 1
+	}
+	if len(b.Prerelease) == 0 {
+		return -1
+	}
+
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		if c := comparePrereleaseIdentifier(a.Prerelease[i], b.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(a.Prerelease) < len(b.Prerelease):
+		return -1
+	case len(a.Prerelease) > len(b.Prerelease):
+		return 1
+	}
+
+	return 0
+}
+
+// Numeric identifiers are compared numerically and have lower precedence than alphanumeric ones
+func comparePrereleaseIdentifier(a string, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+// Compare parses and compares two versions, invalid versions are lower than valid ones
+func Compare(a string, b string) int {
+	aSemver, aErr := ParseSemver(a)
+	bSemver, bErr := ParseSemver(b)
+
+	switch {
+	case aErr != nil && bErr != nil:
+		return strings.Compare(a, b)
+	case aErr != nil:
+		return -1
+	case bErr != nil:
+		return 1
+	}
+
+	return CompareSemver(aSemver, bSemver)
+}
@@ -2,6 +2,8 @@ package version
 
 import (
 	"sort"
+
+	"github.com/icaruk/up-npm/pkg/utils/advisory"
 )
 
 // PackageVersion represents a package with its name and version comparison item
@@ -22,8 +24,14 @@ func SortPackagesByVersionType(versionComparison map[string]VersionComparisonIte
 		})
 	}
 
-	// Sort by version type priority
+	// Sort by vulnerability first, then by version type priority
 	sort.Slice(packages, func(i, j int) bool {
+		severityI := getSeverityPriority(packages[i].VersionComparisonItem)
+		severityJ := getSeverityPriority(packages[j].VersionComparisonItem)
+		if severityI != severityJ {
+			return severityI > severityJ
+		}
+
 		priorityI := getVersionTypePriority(packages[i].VersionType)
 		priorityJ := getVersionTypePriority(packages[j].VersionType)
 		return priorityI < priorityJ
@@ -45,3 +53,11 @@ func getVersionTypePriority(versionType UpgradeType) int {
 		return 4
 	}
 }
+
+// getSeverityPriority returns priority for sorting: vulnerable packages go first, the worse the severity the higher
+func getSeverityPriority(item VersionComparisonItem) int {
+	if !item.IsVulnerable() {
+		return 0
+	}
+	return advisory.SeverityRank(item.Severity()) + 1
+}